@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -14,17 +18,40 @@ import (
 func main() {
 	cfg := config.Load("merchant-service", "7002")
 
+	// Client IPs feed API key IP allowlists, so forwarded headers are only read from
+	// known proxies, taking the rightmost hop that is not one of them
+	clientIP, err := middleware.NewClientIPResolver(cfg.ProxyHeader, cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid PROXY_HEADER/TRUSTED_PROXIES configuration: %v", err)
+	}
+
 	app := fiber.New()
 	app.Use(recover.New())
+	app.Use(clientIP.Handle)
 	app.Use(logger.New())
 	app.Use(middleware.RequestID())
 
 	// CORS is handled by API Gateway - no need to add it here
 
-	routes.Register(app, cfg.ServiceName)
+	stopJobs := routes.Register(app, cfg)
+
+	// On SIGINT or SIGTERM, drain in-flight requests, then stop background jobs so
+	// buffered API key usage is flushed before exit
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			log.Printf("Shutdown did not complete cleanly: %v", err)
+		}
+	}()
 
 	log.Printf("%s listening on :%s", cfg.ServiceName, cfg.Port)
 	if err := app.Listen(":" + cfg.Port); err != nil {
 		log.Fatal(err)
 	}
+	stopJobs()
+	log.Printf("%s stopped", cfg.ServiceName)
 }
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidConfirmation = errors.New("invalid or expired confirmation token")
+	ErrInvalidCiphertext   = errors.New("invalid ciphertext")
+)
+
+// StepUpKeys derives the keys used by two-factor confirmation from one configured secret:
+// one signs short-lived confirmation tokens, the other encrypts TOTP secrets at rest
+type StepUpKeys struct {
+	signing    []byte
+	encryption cipher.AEAD
+	tokenTTL   time.Duration
+}
+
+func NewStepUpKeys(secret string, tokenTTL time.Duration) (*StepUpKeys, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("two-factor secret must be at least 32 characters")
+	}
+	block, err := aes.NewCipher(deriveKey(secret, "totp-secret-encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &StepUpKeys{
+		signing:    deriveKey(secret, "confirmation-token"),
+		encryption: aead,
+		tokenTTL:   tokenTTL,
+	}, nil
+}
+
+// IssueConfirmation returns a token proving merchantID passed a two-factor check, good
+// for one use of action. The token carries a random ID so callers can record its use.
+func (k *StepUpKeys) IssueConfirmation(merchantID int, action string, now time.Time) (string, time.Time, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := now.Add(k.tokenTTL)
+	payload := fmt.Sprintf("%d.%s.%s.%d", merchantID, action, base64.RawURLEncoding.EncodeToString(nonce), expiresAt.Unix())
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + k.sign(payload), expiresAt, nil
+}
+
+// VerifyConfirmation checks that token was issued to merchantID for action and has not
+// expired. It returns the token's ID and expiry; it does not record the use.
+func (k *StepUpKeys) VerifyConfirmation(token string, merchantID int, action string, now time.Time) (string, time.Time, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return "", time.Time{}, ErrInvalidConfirmation
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidConfirmation
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(k.sign(payload))) {
+		return "", time.Time{}, ErrInvalidConfirmation
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 4 {
+		return "", time.Time{}, ErrInvalidConfirmation
+	}
+	expires, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || parts[0] != strconv.Itoa(merchantID) || parts[1] != action || now.Unix() >= expires {
+		return "", time.Time{}, ErrInvalidConfirmation
+	}
+	return parts[2], time.Unix(expires, 0), nil
+}
+
+// Seal encrypts a TOTP secret for storage
+func (k *StepUpKeys) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, k.encryption.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := k.encryption.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func (k *StepUpKeys) Open(ciphertext string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(raw) < k.encryption.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	nonce, sealed := raw[:k.encryption.NonceSize()], raw[k.encryption.NonceSize():]
+	plaintext, err := k.encryption.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+func (k *StepUpKeys) sign(payload string) string {
+	mac := hmac.New(sha256.New, k.signing)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func deriveKey(secret, label string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
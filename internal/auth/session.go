@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidSession = errors.New("invalid session token")
+
+// Session is the verified identity carried by a dashboard token
+type Session struct {
+	Subject    string
+	MerchantID int
+}
+
+// SessionVerifier checks dashboard JWTs issued by the auth service
+type SessionVerifier struct {
+	keys          *KeySet
+	issuer        string
+	audience      string
+	merchantClaim string
+}
+
+// NewSessionVerifier builds a verifier for tokens from issuer meant for audience. Both
+// are required: other services may share the JWKS, and their tokens must not be accepted.
+func NewSessionVerifier(keys *KeySet, issuer, audience, merchantClaim string) *SessionVerifier {
+	if merchantClaim == "" {
+		merchantClaim = "merchant_id"
+	}
+	return &SessionVerifier{keys: keys, issuer: issuer, audience: audience, merchantClaim: merchantClaim}
+}
+
+// Verify validates the token's signature, expiry, issuer and audience and returns
+// the merchant it was issued for
+func (v *SessionVerifier) Verify(ctx context.Context, token string) (*Session, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSession, err)
+	}
+
+	merchantID, err := claimInt(claims[v.merchantClaim])
+	if err != nil || merchantID <= 0 {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidSession, v.merchantClaim)
+	}
+	subject, _ := claims.GetSubject()
+
+	return &Session{Subject: subject, MerchantID: merchantID}, nil
+}
+
+// claimInt accepts merchant IDs encoded either as JSON numbers or strings
+func claimInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("not an integer")
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("unexpected claim type %T", value)
+	}
+}
@@ -0,0 +1,62 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+)
+
+// ErrBankAccountNotFound is returned when the bank does not recognise the account.
+var ErrBankAccountNotFound = errors.New("bank account not found")
+
+// BankAccountDetails is what a bank reports for an account number.
+type BankAccountDetails struct {
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+}
+
+// BankAccountResolver looks up the registered name on a bank account, e.g. through a
+// name-enquiry API, so payouts are only sent to accounts the merchant actually holds.
+type BankAccountResolver interface {
+	ResolveAccount(ctx context.Context, bankCode, accountNumber string) (*BankAccountDetails, error)
+}
+
+// fakeAccountHolders are the names the fake resolver hands out
+var fakeAccountHolders = []string{
+	"ADAEZE OKAFOR",
+	"KWAME MENSAH",
+	"WANJIRU KAMAU",
+	"TUNDE BAKARE",
+	"AMINA BELLO",
+	"KOFI ASANTE",
+	"NJERI MWANGI",
+	"CHIDI EZE",
+}
+
+type fakeBankAccountResolver struct{}
+
+// NewFakeBankAccountResolver returns a resolver for local development and tests. The
+// same bank code and account number always resolve to the same name; account numbers
+// ending in 0000 are reported as not found.
+func NewFakeBankAccountResolver() BankAccountResolver {
+	return fakeBankAccountResolver{}
+}
+
+func (fakeBankAccountResolver) ResolveAccount(ctx context.Context, bankCode, accountNumber string) (*BankAccountDetails, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(accountNumber, "0000") {
+		return nil, ErrBankAccountNotFound
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(bankCode + ":" + accountNumber))
+	return &BankAccountDetails{
+		BankCode:      bankCode,
+		AccountNumber: accountNumber,
+		AccountName:   fakeAccountHolders[h.Sum32()%uint32(len(fakeAccountHolders))],
+	}, nil
+}
@@ -7,3 +7,32 @@ type MerchantBalanceResponse struct {
 	AvailableBalance float64 `json:"available_balance"` // In currency units (e.g., NGN)
 	TotalVolume      float64 `json:"total_volume"`      // In currency units (e.g., NGN)
 }
+
+type PayoutAccountRequest struct {
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"` // Must match the name the bank returns
+	Currency      string `json:"currency"`
+}
+
+type PayoutAccountResponse struct {
+	ID            int    `json:"id"`
+	MerchantID    int    `json:"merchant_id"`
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"` // Masked except on internal payout responses
+	AccountName   string `json:"account_name"`
+	Currency      string `json:"currency"`
+	IsPrimary     bool   `json:"is_primary"`
+	Usable        bool   `json:"usable"`
+	UsableFrom    string `json:"usable_from"` // End of the cooling-off period
+	VerifiedAt    string `json:"verified_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// PayoutResponse tells the payout caller where the deducted funds must be sent
+type PayoutResponse struct {
+	MerchantID    int                   `json:"merchant_id"`
+	Currency      string                `json:"currency"`
+	Amount        float64               `json:"amount"` // In currency units
+	PayoutAccount PayoutAccountResponse `json:"payout_account"`
+}
@@ -2,10 +2,10 @@ package dto
 
 type KYCSubmissionRequest struct {
 	MerchantID        int               `json:"merchant_id"`
-	BusinessType      string            `json:"business_type"` // "registered" or "startup"
+	BusinessType      string            `json:"business_type"` // registered, startup, small_business or individual (light tier only)
 	BusinessName      string            `json:"business_name"`
-	CACNumber         string            `json:"cac_number,omitempty"`
-	TINNumber         string            `json:"tin_number,omitempty"`
+	CACNumber         string            `json:"cac_number,omitempty"` // Deprecated: use registration_number
+	TINNumber         string            `json:"tin_number,omitempty"` // Deprecated: use tax_id
 	BusinessAddress   string            `json:"business_address"`
 	City              string            `json:"city"`
 	State             string            `json:"state"`
@@ -13,10 +13,15 @@ type KYCSubmissionRequest struct {
 	IncorporationDate string            `json:"incorporation_date,omitempty"`
 	BusinessCategory  string            `json:"business_category"`
 	DirectorName      string            `json:"director_name"`
-	DirectorBVN       string            `json:"director_bvn"`
+	DirectorBVN       string            `json:"director_bvn"` // Deprecated: use director_id_number
 	DirectorPhone     string            `json:"director_phone"`
 	DirectorEmail     string            `json:"director_email"`
 	Documents         map[string]string `json:"documents"` // document_type -> file_path/url
+
+	// Country-neutral identifiers; GET /kyc/requirements describes their local formats
+	RegistrationNumber string `json:"registration_number,omitempty"`
+	TaxID              string `json:"tax_id,omitempty"`
+	DirectorIDNumber   string `json:"director_id_number,omitempty"` // BVN in Nigeria, Ghana Card in Ghana, ...
 }
 
 type KYCSubmissionResponse struct {
@@ -28,15 +33,15 @@ type KYCSubmissionResponse struct {
 type KYCStatusUpdateRequest struct {
 	MerchantID  int    `json:"merchant_id"`
 	Status      string `json:"status"` // "approved" or "rejected"
-	ReviewerID  int    `json:"reviewer_id"`
 	ReviewNotes string `json:"review_notes,omitempty"`
 }
 
 type KYCStatusResponse struct {
-	MerchantID  int    `json:"merchant_id"`
-	Status      string `json:"status"`
-	SubmittedAt string `json:"submitted_at,omitempty"`
-	ReviewedAt  string `json:"reviewed_at,omitempty"`
-	ReviewerID  *int   `json:"reviewer_id,omitempty"`
-	ReviewNotes string `json:"review_notes,omitempty"`
+	MerchantID       int    `json:"merchant_id"`
+	Status           string `json:"status"`
+	SubmittedAt      string `json:"submitted_at,omitempty"`
+	ReviewedAt       string `json:"reviewed_at,omitempty"`
+	ReviewerID       *int   `json:"reviewer_id,omitempty"`
+	ReviewNotes      string `json:"review_notes,omitempty"`
+	LiveAPIKeysReady bool   `json:"live_api_keys_ready,omitempty"` // The merchant can now claim live keys from the dashboard
 }
@@ -7,34 +7,266 @@ type MerchantCreateRequest struct {
 	Country      string `json:"country"`
 }
 
+// SubMerchantCreateRequest onboards a seller under a marketplace merchant
+type SubMerchantCreateRequest struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	BusinessName string `json:"business_name"`
+	Country      string `json:"country"`
+	KYCTier      string `json:"kyc_tier,omitempty"` // standard (default) or light
+}
+
 type MerchantCreateResponse struct {
 	ID int `json:"id"`
 }
 
 type MerchantStatusUpdateRequest struct {
-	Status string `json:"status"`
+	Status     string `json:"status"`
+	ReasonCode string `json:"reason_code"`
+	Note       string `json:"note,omitempty"` // Required when reason_code is "other"
+}
+
+type MerchantStatusHistoryResponse struct {
+	ID         int    `json:"id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ReasonCode string `json:"reason_code"`
+	Note       string `json:"note,omitempty"`
+	Actor      string `json:"actor"`
+	CreatedAt  string `json:"created_at"`
 }
 
 type MerchantKYCStatusUpdateRequest struct {
 	KYCStatus string `json:"kyc_status"`
 }
 
+// MerchantUpdateRequest is a partial profile update; omitted fields are left unchanged
+type MerchantUpdateRequest struct {
+	Name         *string `json:"name,omitempty"`
+	Email        *string `json:"email,omitempty"`
+	BusinessName *string `json:"business_name,omitempty"`
+	Country      *string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+
+	// Business profile; an empty string clears the field
+	SupportEmail        *string `json:"support_email,omitempty"`
+	SupportPhone        *string `json:"support_phone,omitempty"` // E.164, e.g. +2348012345678
+	Website             *string `json:"website,omitempty"`
+	LogoURL             *string `json:"logo_url,omitempty"`    // Must be https
+	BrandColor          *string `json:"brand_color,omitempty"` // Hex, e.g. #1A73E8
+	StatementDescriptor *string `json:"statement_descriptor,omitempty"`
+}
+
 type MerchantResponse struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Email        string `json:"email"`
-	BusinessName string `json:"business_name"`
-	Status       string `json:"status"`
-	KYCStatus    string `json:"kyc_status"`
-	Country      string `json:"country"`
-	CanTransact  bool   `json:"can_transact"`
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	BusinessName      string `json:"business_name"`
+	Status            string `json:"status"`
+	KYCStatus         string `json:"kyc_status"`
+	KYCReviewRequired bool   `json:"kyc_review_required"`
+	KYCTier           string `json:"kyc_tier"`
+	ParentID          *int   `json:"parent_id,omitempty"`
+	Country           string `json:"country"`
+	CanTransact       bool   `json:"can_transact"`
+
+	SupportEmail        string `json:"support_email,omitempty"`
+	SupportPhone        string `json:"support_phone,omitempty"`
+	Website             string `json:"website,omitempty"`
+	LogoURL             string `json:"logo_url,omitempty"`
+	BrandColor          string `json:"brand_color,omitempty"`
+	StatementDescriptor string `json:"statement_descriptor,omitempty"`
 }
 
 type APIKeyResponse struct {
-	KeyID       int    `json:"key_id"`
-	Key         string `json:"key,omitempty"` // Only returned when creating/rotating
-	KeyPrefix   string `json:"key_prefix"`
-	Type        string `json:"type"`
-	Environment string `json:"environment"`
-	CreatedAt   string `json:"created_at"`
+	KeyID        int      `json:"key_id"`
+	Key          string   `json:"key,omitempty"` // Only returned when creating/rotating
+	KeyPrefix    string   `json:"key_prefix"`
+	Type         string   `json:"type"`
+	Environment  string   `json:"environment"`
+	Scopes       []string `json:"scopes,omitempty"`
+	AllowedIPs   []string `json:"allowed_ips,omitempty"`
+	ExpiresAt    string   `json:"expires_at,omitempty"`
+	RevokedAt    string   `json:"revoked_at,omitempty"`
+	RevokedBy    string   `json:"revoked_by,omitempty"`
+	RevokeReason string   `json:"revoke_reason,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+type APIKeyRevokeRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type RestrictedAPIKeyCreateRequest struct {
+	Environment string   `json:"environment"` // "test" or "live", defaults to "test"
+	Scopes      []string `json:"scopes"`      // e.g. ["payment_links:read", "balance:read"]
+}
+
+type APIKeyRotateRequest struct {
+	Type        string `json:"type"`                   // "public" or "secret", defaults to "secret"
+	Environment string `json:"environment"`            // "test" or "live", defaults to "test"
+	GracePeriod string `json:"grace_period,omitempty"` // e.g. "24h" or "7d"; "0" expires the old key immediately
+	ExpiresAt   string `json:"expires_at,omitempty"`   // RFC3339; takes precedence over grace_period
+}
+
+type APIKeyRotateResponse struct {
+	APIKeyResponse
+	PreviousKeysExpireAt string `json:"previous_keys_expire_at"`
+	PreviousKeysAffected int64  `json:"previous_keys_affected"`
+}
+
+type APIKeyDailyUsage struct {
+	Date     string `json:"date"` // YYYY-MM-DD (UTC)
+	Requests int64  `json:"requests"`
+	Errors   int64  `json:"errors"`
+}
+
+type APIKeyUsageResponse struct {
+	KeyID         int                `json:"key_id"`
+	KeyPrefix     string             `json:"key_prefix"`
+	IsActive      bool               `json:"is_active"`
+	LastUsedAt    string             `json:"last_used_at,omitempty"`
+	LastUsedIP    string             `json:"last_used_ip,omitempty"`
+	Days          int                `json:"days"`
+	TotalRequests int64              `json:"total_requests"`
+	TotalErrors   int64              `json:"total_errors"`
+	Daily         []APIKeyDailyUsage `json:"daily"`
+}
+
+type APIKeyIPAllowlistRequest struct {
+	AllowedIPs []string `json:"allowed_ips"` // IPs or CIDR ranges; empty clears the allowlist
+}
+
+type APIKeyIPAllowlistResponse struct {
+	KeyID      int      `json:"key_id"`
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+type AdminCreateRequest struct {
+	Email string   `json:"email"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"` // kyc_reviewer, ops, finance, super_admin
+}
+
+type AdminRolesUpdateRequest struct {
+	Roles []string `json:"roles"`
+}
+
+type AdminResponse struct {
+	ID          int      `json:"id"`
+	Email       string   `json:"email"`
+	Name        string   `json:"name"`
+	Roles       []string `json:"roles"`
+	Token       string   `json:"token,omitempty"` // Only returned when the admin is created
+	TokenPrefix string   `json:"token_prefix"`
+	IsActive    bool     `json:"is_active"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool   `json:"enabled"`
+	EnabledAt              string `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`      // Base32; shown only during enrollment
+	OTPAuthURL string `json:"otpauth_url"` // Render as a QR code for authenticator apps
+}
+
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code,omitempty"`          // 6-digit TOTP code
+	RecoveryCode string `json:"recovery_code,omitempty"` // Single-use alternative to code
+}
+
+type TwoFactorActivateResponse struct {
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"recovery_codes"` // Shown only once
+}
+
+type TwoFactorConfirmRequest struct {
+	TwoFactorCodeRequest
+	Action string `json:"action"` // The action the token unlocks, e.g. api_keys:rotate
+}
+
+type TwoFactorConfirmationResponse struct {
+	ConfirmationToken string `json:"confirmation_token"` // Send as X-Confirmation-Token; good for one request
+	Action            string `json:"action"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+type MerchantSearchRequest struct {
+	Statuses    []string
+	KYCStatuses []string
+	Countries   []string
+	CreatedFrom string // RFC3339 or YYYY-MM-DD, inclusive
+	CreatedTo   string // RFC3339 or YYYY-MM-DD; a bare date includes the whole day
+	Query       string
+	ParentID    *int
+	Cursor      string
+	Limit       int
+}
+
+type MerchantListResponse struct {
+	Data       []MerchantResponse `json:"data"`
+	Total      int                `json:"total"`
+	Limit      int                `json:"limit"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type MerchantCloseRequest struct {
+	ReasonCode string `json:"reason_code,omitempty"` // Defaults to merchant_request when the merchant closes its own account
+	Note       string `json:"note,omitempty"`
+}
+
+type MerchantCloseResponse struct {
+	ID                      int    `json:"id"`
+	Status                  string `json:"status"`
+	ClosedAt                string `json:"closed_at"`
+	APIKeysRevoked          int64  `json:"api_keys_revoked"`
+	PaymentLinksDeactivated int64  `json:"payment_links_deactivated"`
+	// PartialFailure is set when a cleanup step failed; closing the merchant again retries it
+	PartialFailure bool     `json:"partial_failure,omitempty"`
+	FailedSteps    []string `json:"failed_steps,omitempty"` // "api_keys" and/or "payment_links"
+}
+
+type TeamInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // owner, admin, developer, finance
+}
+
+type TeamRoleUpdateRequest struct {
+	Role string `json:"role"`
+}
+
+// TeamInvitationAcceptRequest is sent by the auth service once the invitee has signed in
+type TeamInvitationAcceptRequest struct {
+	Token  string `json:"token"`
+	UserID string `json:"user_id"`
+	Email  string `json:"email"` // Verified email of the signed-in user; must match the invitation
+}
+
+type TeamMemberResponse struct {
+	ID                  int    `json:"id"`
+	MerchantID          int    `json:"merchant_id"`
+	Email               string `json:"email"`
+	UserID              string `json:"user_id,omitempty"`
+	Role                string `json:"role"`
+	Status              string `json:"status"`
+	InvitationToken     string `json:"invitation_token,omitempty"` // Only returned when the invitation is created
+	InvitationExpiresAt string `json:"invitation_expires_at,omitempty"`
+	InvitedBy           string `json:"invited_by,omitempty"`
+	AcceptedAt          string `json:"accepted_at,omitempty"`
+	CreatedAt           string `json:"created_at"`
+}
+
+type MerchantCurrencyRequest struct {
+	Currency string `json:"currency"` // ISO 4217 code, e.g. GHS
+}
+
+type MerchantCurrencyResponse struct {
+	MerchantID int    `json:"merchant_id"`
+	Currency   string `json:"currency"`
+	IsDefault  bool   `json:"is_default"`
+	EnabledBy  string `json:"enabled_by"`
+	CreatedAt  string `json:"created_at"`
 }
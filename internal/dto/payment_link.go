@@ -9,16 +9,28 @@ type CreatePaymentLinkRequest struct {
 }
 
 type PaymentLinkResponse struct {
-	ID          int    `json:"id"`
-	MerchantID  int    `json:"merchant_id"`
-	Mode        string `json:"mode"`
-	Amount      *int64 `json:"amount,omitempty"`
-	Currency    string `json:"currency"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	URL         string `json:"url"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID          int              `json:"id"`
+	MerchantID  int              `json:"merchant_id"`
+	Mode        string           `json:"mode"`
+	Amount      *int64           `json:"amount,omitempty"`
+	Currency    string           `json:"currency"`
+	Description string           `json:"description"`
+	Status      string           `json:"status"`
+	URL         string           `json:"url"`
+	Checkout    *CheckoutProfile `json:"checkout,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+	UpdatedAt   string           `json:"updated_at"`
+}
+
+// CheckoutProfile is the merchant branding and contact details shown on the checkout page
+type CheckoutProfile struct {
+	BusinessName        string `json:"business_name"`
+	LogoURL             string `json:"logo_url,omitempty"`
+	BrandColor          string `json:"brand_color,omitempty"`
+	SupportEmail        string `json:"support_email,omitempty"`
+	SupportPhone        string `json:"support_phone,omitempty"`
+	Website             string `json:"website,omitempty"`
+	StatementDescriptor string `json:"statement_descriptor,omitempty"`
 }
 
 type ListPaymentLinksResponse struct {
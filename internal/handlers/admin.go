@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+type AdminHandler struct {
+	svc *services.AdminService
+}
+
+func NewAdminHandler(svc *services.AdminService) *AdminHandler {
+	return &AdminHandler{svc: svc}
+}
+
+func (h *AdminHandler) List(c *fiber.Ctx) error {
+	resp, err := h.svc.List(c.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list admins")
+	}
+	return c.JSON(resp)
+}
+
+// Create registers an admin; the returned token is shown only once
+func (h *AdminHandler) Create(c *fiber.Ctx) error {
+	var req dto.AdminCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Create(c.Context(), req)
+	if err != nil {
+		return adminError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *AdminHandler) UpdateRoles(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid admin ID")
+	}
+	var req dto.AdminRolesUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	if err := h.svc.UpdateRoles(c.Context(), id, req); err != nil {
+		return adminError(err)
+	}
+	return c.JSON(fiber.Map{"id": id, "roles": req.Roles})
+}
+
+func (h *AdminHandler) Deactivate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid admin ID")
+	}
+	if err := h.svc.Deactivate(c.Context(), id); err != nil {
+		return adminError(err)
+	}
+	return c.JSON(fiber.Map{"id": id, "is_active": false})
+}
+
+// Register registers the admin management routes
+func (h *AdminHandler) Register(app *fiber.App) {
+	admins := app.Group("/admins")
+	admins.Get("/", h.List)
+	admins.Post("/", h.Create)
+	admins.Put("/:id/roles", h.UpdateRoles)
+	admins.Delete("/:id", h.Deactivate)
+}
+
+func adminError(err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, repositories.ErrAdminNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repositories.ErrAdminExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		log.Printf("ERROR: admin operation failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "admin operation failed")
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+type APIKeyHandler struct {
+	svc *services.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp := h.svc.List(c.Context(), id)
+	return c.JSON(resp)
+}
+
+// Rotate issues a replacement key; the old key keeps working until its grace period ends
+func (h *APIKeyHandler) Rotate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.APIKeyRotateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+	resp, err := h.svc.Rotate(c.Context(), id, req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(resp)
+}
+
+// CreateRestricted issues a key limited to a set of scopes
+func (h *APIKeyHandler) CreateRestricted(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.RestrictedAPIKeyCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.CreateRestricted(c.Context(), id, req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// IssueLive hands a merchant that passed KYC its live key pair. The full keys are shown
+// only in this response.
+func (h *APIKeyHandler) IssueLive(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.IssueLiveKeys(c.Context(), id)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// IssueTest hands a merchant its test key pair. The full keys are shown only in this
+// response.
+func (h *APIKeyHandler) IssueTest(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.IssueTestKeys(c.Context(), id)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// ListExpiring returns keys that still work but are scheduled to expire
+func (h *APIKeyHandler) ListExpiring(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.ListPendingExpiry(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list expiring api keys")
+	}
+	return c.JSON(resp)
+}
+
+// Expire ends the grace period of a rotated key early
+func (h *APIKeyHandler) Expire(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	keyID, err := c.ParamsInt("key_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key ID")
+	}
+	if err := h.svc.ExpireNow(c.Context(), id, keyID); err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(fiber.Map{
+		"message": "api key expired",
+		"key_id":  keyID,
+	})
+}
+
+// Usage returns per-key request statistics so merchants can spot stale keys
+func (h *APIKeyHandler) Usage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	keyID, err := c.ParamsInt("key_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key ID")
+	}
+	resp, err := h.svc.Usage(c.Context(), id, keyID, c.QueryInt("days", 0))
+	if err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to load api key usage")
+	}
+	return c.JSON(resp)
+}
+
+// GetIPAllowlist returns the IP ranges a key may be used from
+func (h *APIKeyHandler) GetIPAllowlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	keyID, err := c.ParamsInt("key_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key ID")
+	}
+	resp, err := h.svc.GetAllowedIPs(c.Context(), id, keyID)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(resp)
+}
+
+// UpdateIPAllowlist replaces the IP ranges a secret or restricted key may be used from
+func (h *APIKeyHandler) UpdateIPAllowlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	keyID, err := c.ParamsInt("key_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key ID")
+	}
+	var req dto.APIKeyIPAllowlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.UpdateAllowedIPs(c.Context(), id, keyID, req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(resp)
+}
+
+// Revoke deactivates a single key
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	keyID, err := c.ParamsInt("key_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key ID")
+	}
+	var req dto.APIKeyRevokeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = c.Query("reason")
+	}
+	resp, err := h.svc.Revoke(c.Context(), id, keyID, middleware.ActorFromContext(c), req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(resp)
+}
+
+// ListRevoked returns keys that have been revoked
+func (h *APIKeyHandler) ListRevoked(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.ListRevoked(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list revoked api keys")
+	}
+	return c.JSON(resp)
+}
+
+// Register registers the API key routes
+func (h *APIKeyHandler) Register(app *fiber.App) {
+	merchants := app.Group("/merchants")
+	merchants.Get("/:id/api-keys", h.List)
+	merchants.Get("/:id/api-keys/expiring", h.ListExpiring)
+	merchants.Get("/:id/api-keys/revoked", h.ListRevoked)
+	merchants.Post("/:id/api-keys/rotate", h.Rotate)
+	merchants.Post("/:id/api-keys/restricted", h.CreateRestricted)
+	merchants.Post("/:id/api-keys/test", h.IssueTest)
+	merchants.Post("/:id/api-keys/live", h.IssueLive)
+	merchants.Post("/:id/api-keys/:key_id/expire", h.Expire)
+	merchants.Delete("/:id/api-keys/:key_id", h.Revoke)
+	merchants.Get("/:id/api-keys/:key_id/usage", h.Usage)
+	merchants.Get("/:id/api-keys/:key_id/ip-allowlist", h.GetIPAllowlist)
+	merchants.Put("/:id/api-keys/:key_id/ip-allowlist", h.UpdateIPAllowlist)
+}
+
+func apiKeyError(err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, repositories.ErrAPIKeyNotFound), errors.Is(err, repositories.ErrMerchantNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrLiveKeyNotPermitted):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrLiveKeysIssued), errors.Is(err, services.ErrTestKeysIssued):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		log.Printf("ERROR: api key operation failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "api key operation failed")
+	}
+}
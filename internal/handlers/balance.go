@@ -1,22 +1,27 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"math"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
 	"github.com/kodra-pay/merchant-service/internal/services"
 )
 
 type BalanceHandler struct {
-	svc *services.BalanceService
+	svc         *services.BalanceService
+	merchantSvc *services.MerchantService
 }
 
-func NewBalanceHandler(svc *services.BalanceService) *BalanceHandler {
-	return &BalanceHandler{svc: svc}
+func NewBalanceHandler(svc *services.BalanceService, merchantSvc *services.MerchantService) *BalanceHandler {
+	return &BalanceHandler{svc: svc, merchantSvc: merchantSvc}
 }
 
-// GetBalance returns the merchant's balance
+// GetBalance returns the merchant's balance in ?currency=, defaulting to its default currency
 func (h *BalanceHandler) GetBalance(c *fiber.Ctx) error {
 	merchantIDStr := c.Params("id")
 	if merchantIDStr == "" {
@@ -27,7 +32,10 @@ func (h *BalanceHandler) GetBalance(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id must be a number")
 	}
 
-	currency := c.Query("currency", "NGN")
+	currency, err := h.resolveCurrency(c, merchantID, c.Query("currency"))
+	if err != nil {
+		return err
+	}
 	balance := h.svc.GetBalance(c.Context(), merchantID, currency)
 	return c.JSON(balance)
 }
@@ -53,13 +61,19 @@ func (h *BalanceHandler) SettleBalance(c *fiber.Ctx) error {
 	if payload.MerchantID <= 0 || payload.Amount <= 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id and positive amount are required")
 	}
-	if payload.Currency == "" {
-		payload.Currency = "NGN"
+	currency, err := h.resolveCurrency(c, payload.MerchantID, payload.Currency)
+	if err != nil {
+		return err
 	}
+	payload.Currency = currency
 
 	amountKobo := int64(math.Round(payload.Amount * 100))
+	log.Printf("Balance settle requested by %s: merchant=%d currency=%s amount=%d", middleware.ServiceCallerFromContext(c), payload.MerchantID, payload.Currency, amountKobo)
 
 	if err := h.svc.Settle(c.Context(), payload.MerchantID, payload.Currency, amountKobo); err != nil {
+		if errors.Is(err, repositories.ErrMerchantClosed) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	return c.SendStatus(fiber.StatusNoContent)
@@ -78,19 +92,25 @@ func (h *BalanceHandler) RecordBalance(c *fiber.Ctx) error {
 	if payload.MerchantID <= 0 || payload.Amount <= 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id and positive amount are required")
 	}
-	if payload.Currency == "" {
-		payload.Currency = "NGN"
+	currency, err := h.resolveCurrency(c, payload.MerchantID, payload.Currency)
+	if err != nil {
+		return err
 	}
+	payload.Currency = currency
 
 	amountKobo := int64(math.Round(payload.Amount * 100))
+	log.Printf("Balance record requested by %s: merchant=%d currency=%s amount=%d", middleware.ServiceCallerFromContext(c), payload.MerchantID, payload.Currency, amountKobo)
 
 	if err := h.svc.RecordTransaction(c.Context(), payload.MerchantID, payload.Currency, amountKobo); err != nil {
+		if errors.Is(err, repositories.ErrMerchantClosed) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// ProcessPayout deducts from a merchant's available balance (internal use)
+// ProcessPayout deducts from a merchant's available balance and returns the payout account to pay (internal use)
 func (h *BalanceHandler) ProcessPayout(c *fiber.Ctx) error {
 	var payload struct {
 		MerchantID int     `json:"merchant_id"`
@@ -103,14 +123,40 @@ func (h *BalanceHandler) ProcessPayout(c *fiber.Ctx) error {
 	if payload.MerchantID <= 0 || payload.Amount <= 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id and positive amount are required")
 	}
-	if payload.Currency == "" {
-		payload.Currency = "NGN"
+	currency, err := h.resolveCurrency(c, payload.MerchantID, payload.Currency)
+	if err != nil {
+		return err
 	}
+	payload.Currency = currency
 
 	amountKobo := int64(math.Round(payload.Amount * 100))
+	log.Printf("Balance payout requested by %s: merchant=%d currency=%s amount=%d", middleware.ServiceCallerFromContext(c), payload.MerchantID, payload.Currency, amountKobo)
 
-	if err := h.svc.ProcessPayout(c.Context(), payload.MerchantID, payload.Currency, amountKobo); err != nil {
+	resp, err := h.svc.ProcessPayout(c.Context(), payload.MerchantID, payload.Currency, amountKobo)
+	if err != nil {
+		if errors.Is(err, services.ErrNoUsablePayoutAccount) || errors.Is(err, repositories.ErrMerchantClosed) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
-	return c.SendStatus(fiber.StatusNoContent)
+	return c.JSON(resp)
+}
+
+// resolveCurrency checks that the merchant has currency enabled, defaulting to the
+// merchant's default currency when none is given
+func (h *BalanceHandler) resolveCurrency(c *fiber.Ctx, merchantID int, currency string) (string, error) {
+	resolved, err := h.merchantSvc.ResolveCurrency(c.Context(), merchantID, currency)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return "", fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return "", fiber.NewError(fiber.StatusNotFound, "merchant not found")
+		default:
+			log.Printf("ERROR: failed to resolve currency for merchant %d: %v", merchantID, err)
+			return "", fiber.NewError(fiber.StatusInternalServerError, "failed to load merchant currencies")
+		}
+	}
+	return resolved, nil
 }
@@ -1,13 +1,17 @@
 package handlers
 
 import (
-	"strconv"
+	"encoding/json"
+	"errors"
+	"log"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
 	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
 	"github.com/kodra-pay/merchant-service/internal/services"
 )
 
@@ -19,8 +23,128 @@ func NewMerchantHandler(svc *services.MerchantService) *MerchantHandler {
 	return &MerchantHandler{svc: svc}
 }
 
+// List searches merchants for the ops dashboard. Filters: status, kyc_status and country
+// (comma separated), created_from, created_to, q; paging: limit and cursor.
 func (h *MerchantHandler) List(c *fiber.Ctx) error {
-	resp := h.svc.List(c.Context())
+	resp, err := h.svc.Search(c.Context(), dto.MerchantSearchRequest{
+		Statuses:    splitCommaSeparatedString(c.Query("status")),
+		KYCStatuses: splitCommaSeparatedString(c.Query("kyc_status")),
+		Countries:   splitCommaSeparatedString(c.Query("country")),
+		CreatedFrom: c.Query("created_from"),
+		CreatedTo:   c.Query("created_to"),
+		Query:       c.Query("q"),
+		Cursor:      c.Query("cursor"),
+		Limit:       c.QueryInt("limit", 0),
+	})
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list merchants")
+	}
+	return c.JSON(resp)
+}
+
+// CreateSubMerchant onboards a seller under the marketplace merchant in the path
+func (h *MerchantHandler) CreateSubMerchant(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.SubMerchantCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.CreateSubMerchant(c.Context(), id, req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		var conflictErr *services.MerchantConflictError
+		switch {
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &conflictErr):
+			return merchantConflict(c, conflictErr)
+		case errors.Is(err, services.ErrNestedSubMerchant), errors.Is(err, services.ErrParentNotActive):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		default:
+			log.Printf("ERROR: failed to create sub-merchant of %d: %v", id, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to create sub-merchant")
+		}
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// ListSubMerchants pages through a marketplace merchant's sub-merchants
+func (h *MerchantHandler) ListSubMerchants(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.ListSubMerchants(c.Context(), id, dto.MerchantSearchRequest{
+		Statuses:    splitCommaSeparatedString(c.Query("status")),
+		KYCStatuses: splitCommaSeparatedString(c.Query("kyc_status")),
+		Query:       c.Query("q"),
+		Cursor:      c.Query("cursor"),
+		Limit:       c.QueryInt("limit", 0),
+	})
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list sub-merchants")
+	}
+	return c.JSON(resp)
+}
+
+// ListCurrencies lists the currencies a merchant can transact and settle in
+func (h *MerchantHandler) ListCurrencies(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.ListCurrencies(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMerchantNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list currencies")
+	}
+	return c.JSON(resp)
+}
+
+// EnableCurrency adds a currency for a merchant and provisions its wallet, settlement
+// config and balance (admin only)
+func (h *MerchantHandler) EnableCurrency(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.MerchantCurrencyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, created, err := h.svc.EnableCurrency(c.Context(), id, middleware.ActorFromContext(c), req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		case errors.Is(err, services.ErrCurrencyProvisioning):
+			return fiber.NewError(fiber.StatusBadGateway, err.Error())
+		default:
+			log.Printf("ERROR: failed to enable currency for merchant %d: %v", id, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to enable currency")
+		}
+	}
+	if created {
+		return c.Status(fiber.StatusCreated).JSON(resp)
+	}
 	return c.JSON(resp)
 }
 
@@ -40,6 +164,15 @@ func (h *MerchantHandler) ListMerchantsByKYCStatuses(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
+// merchantConflict reports a duplicate merchant without revealing the existing merchant's ID
+func merchantConflict(c *fiber.Ctx, err *services.MerchantConflictError) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error":   "merchant_exists",
+		"message": err.Error(),
+		"field":   err.Field,
+	})
+}
+
 func splitCommaSeparatedString(s string) []string {
 	var result []string
 	parts := strings.Split(s, ",")
@@ -57,8 +190,21 @@ func (h *MerchantHandler) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
-	resp := h.svc.Create(c.Context(), req)
-	return c.JSON(resp)
+	resp, err := h.svc.Create(c.Context(), req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		var conflictErr *services.MerchantConflictError
+		switch {
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &conflictErr):
+			return merchantConflict(c, conflictErr)
+		default:
+			log.Printf("ERROR: failed to create merchant: %v", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to create merchant")
+		}
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
 }
 
 func (h *MerchantHandler) Get(c *fiber.Ctx) error {
@@ -73,63 +219,157 @@ func (h *MerchantHandler) Get(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
-func (h *MerchantHandler) UpdateStatus(c *fiber.Ctx) error {
+// merchantProtectedFields cannot be changed through a profile update
+var merchantProtectedFields = []string{"id", "status", "kyc_status", "kyc_review_required", "can_transact"}
+
+// Update applies a partial update to the merchant's profile
+func (h *MerchantHandler) Update(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
 	}
-	var req dto.MerchantStatusUpdateRequest
-	if err := c.BodyParser(&req); err != nil {
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &raw); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
-	resp := h.svc.UpdateStatus(c.Context(), id, req)
+	for _, field := range merchantProtectedFields {
+		if _, ok := raw[field]; ok {
+			return fiber.NewError(fiber.StatusBadRequest, field+" cannot be changed through this endpoint")
+		}
+	}
+
+	var req dto.MerchantUpdateRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.UpdateProfile(c.Context(), id, req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		var conflictErr *services.MerchantConflictError
+		switch {
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		case errors.As(err, &conflictErr):
+			return merchantConflict(c, conflictErr)
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to update merchant")
+		}
+	}
 	return c.JSON(resp)
 }
 
-func (h *MerchantHandler) UpdateKYCStatus(c *fiber.Ctx) error {
+// UpdateStatus moves a merchant to a new status with a reason code
+func (h *MerchantHandler) UpdateStatus(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
 	}
-	var req dto.MerchantKYCStatusUpdateRequest
+	var req dto.MerchantStatusUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
-	resp := h.svc.UpdateKYCStatus(c.Context(), id, req)
+	resp, err := h.svc.UpdateStatus(c.Context(), id, middleware.ActorFromContext(c), req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		var transitionErr *services.StatusTransitionError
+		switch {
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &transitionErr), errors.Is(err, repositories.ErrStatusChanged):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to update merchant status")
+		}
+	}
 	return c.JSON(resp)
 }
 
-func (h *MerchantHandler) ListAPIKeys(c *fiber.Ctx) error {
+// Close offboards a merchant. Merchants close their own account; admins need the ops role.
+func (h *MerchantHandler) Close(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
 	}
-	resp := h.svc.ListAPIKeys(c.Context(), id)
+	var req dto.MerchantCloseRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+	if admin, ok := middleware.AdminFromContext(c); ok {
+		if !admin.HasRole(models.AdminRoleOps) {
+			return fiber.NewError(fiber.StatusForbidden, "closing a merchant requires the ops role")
+		}
+	} else {
+		req.ReasonCode = string(models.ReasonMerchantRequest)
+	}
+
+	resp, err := h.svc.Close(c.Context(), id, middleware.ActorFromContext(c), req)
+	if err != nil {
+		var validationErr *services.ValidationError
+		var transitionErr *services.StatusTransitionError
+		var balanceErr *services.OutstandingBalanceError
+		switch {
+		case errors.As(err, &balanceErr):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    "outstanding_balance",
+				"message":  err.Error(),
+				"balances": balanceErr.Balances,
+			})
+		case errors.As(err, &validationErr):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &transitionErr), errors.Is(err, repositories.ErrStatusChanged),
+			errors.Is(err, repositories.ErrMerchantHasSubMerchants):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repositories.ErrMerchantNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		default:
+			log.Printf("ERROR: failed to close merchant %d: %v", id, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to close merchant")
+		}
+	}
 	return c.JSON(resp)
 }
 
-func (h *MerchantHandler) RotateAPIKey(c *fiber.Ctx) error {
+// StatusHistory lists a merchant's status changes for support
+func (h *MerchantHandler) StatusHistory(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
 	}
-	resp := h.svc.RotateAPIKey(c.Context(), id)
+	resp, err := h.svc.StatusHistory(c.Context(), id, c.QueryInt("limit", 0))
+	if err != nil {
+		if errors.Is(err, repositories.ErrMerchantNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to load status history")
+	}
 	return c.JSON(resp)
 }
 
-// Me returns the current merchant profile by merchant_id passed via query or header
-func (h *MerchantHandler) Me(c *fiber.Ctx) error {
-	merchantIDStr := c.Query("merchant_id")
-	if merchantIDStr == "" {
-		merchantIDStr = c.Get("X-Merchant-Id")
+func (h *MerchantHandler) UpdateKYCStatus(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
 	}
-	if merchantIDStr == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "merchant_id is required")
+	var req dto.MerchantKYCStatusUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
+	resp := h.svc.UpdateKYCStatus(c.Context(), id, req)
+	return c.JSON(resp)
+}
 
-	merchantID, err := strconv.Atoi(merchantIDStr)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "invalid merchant_id")
+// Me returns the profile of the merchant identified by the verified session or API key
+func (h *MerchantHandler) Me(c *fiber.Ctx) error {
+	merchantID, ok := middleware.MerchantIDFromContext(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
 	}
 
 	resp := h.svc.Get(c.Context(), merchantID)
@@ -147,10 +387,15 @@ func (h *MerchantHandler) Register(app *fiber.App) {
 	merchants.Get("/me", h.Me)
 	merchants.Post("/", h.Create)
 	merchants.Get("/:id", h.Get)
+	merchants.Patch("/:id", h.Update)
 	merchants.Put("/:id/status", h.UpdateStatus)
+	merchants.Post("/:id/close", h.Close)
+	merchants.Get("/:id/status-history", h.StatusHistory)
+	merchants.Post("/:id/sub-merchants", h.CreateSubMerchant)
+	merchants.Get("/:id/sub-merchants", h.ListSubMerchants)
+	merchants.Get("/:id/currencies", h.ListCurrencies)
+	merchants.Post("/:id/currencies", h.EnableCurrency)
 	merchants.Put("/:id/kyc-status", h.UpdateKYCStatus) // New route for updating KYC status
-	merchants.Get("/:id/api-keys", h.ListAPIKeys)
-	merchants.Post("/:id/api-keys/rotate", h.RotateAPIKey)
 
 	// Singular alias
 	singular := app.Group("/merchant")
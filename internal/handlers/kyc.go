@@ -3,18 +3,21 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
 	"github.com/kodra-pay/merchant-service/internal/services"
 )
 
 type KYCHandler struct {
 	merchantService *services.MerchantService
 	kycService      *services.KYCService
+	access          *middleware.MerchantAccess
 }
 
-func NewKYCHandler(merchantService *services.MerchantService, kycService *services.KYCService) *KYCHandler {
+func NewKYCHandler(merchantService *services.MerchantService, kycService *services.KYCService, access *middleware.MerchantAccess) *KYCHandler {
 	return &KYCHandler{
 		merchantService: merchantService,
 		kycService:      kycService,
+		access:          access,
 	}
 }
 
@@ -24,6 +27,14 @@ func (h *KYCHandler) SubmitKYC(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
+	// Marketplace merchants may submit on behalf of their sub-merchants
+	if authMerchantID, ok := middleware.MerchantIDFromContext(c); ok {
+		if req.MerchantID == 0 {
+			req.MerchantID = authMerchantID
+		} else if !h.access.CanAccessMerchant(c, req.MerchantID) {
+			return fiber.NewError(fiber.StatusForbidden, "merchant_id does not match the authenticated merchant")
+		}
+	}
 
 	submission, err := h.kycService.Submit(c.Context(), req)
 	if err != nil {
@@ -32,6 +43,16 @@ func (h *KYCHandler) SubmitKYC(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(submission)
 }
 
+// GetRequirements describes the fields and documents a KYC submission needs
+// GET /kyc/requirements?country=GH&business_type=registered
+func (h *KYCHandler) GetRequirements(c *fiber.Ctx) error {
+	requirements, err := h.kycService.Requirements(c.Query("country"), c.Query("business_type"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(requirements)
+}
+
 // GetKYCStatus returns the current KYC status for a merchant
 func (h *KYCHandler) GetKYCStatus(c *fiber.Ctx) error {
 	merchantID, err := c.ParamsInt("merchant_id")
@@ -61,7 +82,7 @@ func (h *KYCHandler) GetKYCStatus(c *fiber.Ctx) error {
 	return c.JSON(status)
 }
 
-// UpdateKYCStatus updates the KYC status (admin only)
+// UpdateKYCStatus records a KYC review decision (kyc_reviewer role)
 func (h *KYCHandler) UpdateKYCStatus(c *fiber.Ctx) error {
 	var req dto.KYCStatusUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -72,30 +93,33 @@ func (h *KYCHandler) UpdateKYCStatus(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id is required")
 	}
 
-	// Convert req.ReviewerID (int) to *int for service layer
+	// The reviewer is always the authenticated admin
 	var reviewerID *int
-	if req.ReviewerID != 0 {
-		reviewerID = &req.ReviewerID
+	if admin, ok := middleware.AdminFromContext(c); ok {
+		reviewerID = &admin.ID
 	}
 
-	if err := h.kycService.UpdateStatus(c.Context(), req.MerchantID, req.Status, reviewerID, &req.ReviewNotes); err != nil {
+	liveKeysReady, err := h.kycService.UpdateStatus(c.Context(), req.MerchantID, req.Status, reviewerID, &req.ReviewNotes)
+	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
 	return c.JSON(dto.KYCStatusResponse{
-		MerchantID:  req.MerchantID,
-		Status:      req.Status,
-		ReviewerID:  reviewerID,
-		ReviewNotes: req.ReviewNotes,
+		MerchantID:       req.MerchantID,
+		Status:           req.Status,
+		ReviewerID:       reviewerID,
+		ReviewNotes:      req.ReviewNotes,
+		LiveAPIKeysReady: liveKeysReady,
 	})
 }
 
 // Register registers the KYC routes
 func (h *KYCHandler) Register(app *fiber.App) {
 	kyc := app.Group("/kyc")
+	kyc.Get("/requirements", h.GetRequirements)
 	kyc.Post("/submit", h.SubmitKYC)
 	kyc.Get("/status/:merchant_id", h.GetKYCStatus)
-	kyc.Post("/update", h.UpdateKYCStatus) // Admin only - should be protected by auth middleware
+	kyc.Post("/update", h.UpdateKYCStatus) // Admin only - guarded by RequireRole in routes
 	kyc.Get("/pending", h.ListPending)
 }
 
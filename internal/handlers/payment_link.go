@@ -5,16 +5,18 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
 	"github.com/kodra-pay/merchant-service/internal/repositories"
 	"github.com/kodra-pay/merchant-service/internal/services"
 )
 
 type PaymentLinkHandler struct {
-	svc *services.PaymentLinkService
+	svc    *services.PaymentLinkService
+	access *middleware.MerchantAccess
 }
 
-func NewPaymentLinkHandler(svc *services.PaymentLinkService) *PaymentLinkHandler {
-	return &PaymentLinkHandler{svc: svc}
+func NewPaymentLinkHandler(svc *services.PaymentLinkService, access *middleware.MerchantAccess) *PaymentLinkHandler {
+	return &PaymentLinkHandler{svc: svc, access: access}
 }
 
 func (h *PaymentLinkHandler) Register(app *fiber.App) {
@@ -30,6 +32,16 @@ func (h *PaymentLinkHandler) CreatePaymentLink(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
 
+	// An authenticated API key decides which merchant the link belongs to; marketplace
+	// merchants may also create links for their sub-merchants
+	if authMerchantID, ok := middleware.MerchantIDFromContext(c); ok {
+		if req.MerchantID == 0 {
+			req.MerchantID = authMerchantID
+		} else if !h.access.CanAccessMerchant(c, req.MerchantID) {
+			return fiber.NewError(fiber.StatusForbidden, "merchant_id does not match the authenticated merchant")
+		}
+	}
+
 	// Validate required fields
 	if req.MerchantID <= 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "merchant_id is required")
@@ -69,7 +81,7 @@ func (h *PaymentLinkHandler) GetPaymentLink(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to get payment link")
 	}
-	if link == nil {
+	if link == nil || !h.access.CanAccessMerchant(c, link.MerchantID) {
 		return fiber.NewError(fiber.StatusNotFound, "payment link not found")
 	}
 
@@ -113,16 +125,18 @@ func (h *PaymentLinkHandler) DeletePaymentLink(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "payment link id must be a number")
 	}
-	merchantIDStr := c.Query("merchant_id")
-	merchantID := 0
-	if merchantIDStr != "" {
-		merchantID, err = strconv.Atoi(merchantIDStr)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "merchant_id must be a number")
-		}
+
+	// Merchants delete their own and their sub-merchants' links; admins delete on
+	// behalf of the link's owner
+	link, err := h.svc.GetPaymentLink(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to get payment link")
+	}
+	if link == nil || !h.access.CanAccessMerchant(c, link.MerchantID) {
+		return fiber.NewError(fiber.StatusNotFound, repositories.ErrPaymentLinkNotFound.Error())
 	}
 
-	if err := h.svc.DeletePaymentLink(c.Context(), id, merchantID); err != nil {
+	if err := h.svc.DeletePaymentLink(c.Context(), id, link.MerchantID); err != nil {
 		if err == repositories.ErrPaymentLinkNotFound {
 			return fiber.NewError(fiber.StatusNotFound, err.Error())
 		}
@@ -1,25 +1,30 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
 	"github.com/kodra-pay/merchant-service/internal/services"
 )
 
 type PaymentOptionsHandler struct {
 	paymentSvc    *services.PaymentOptionsService
 	settlementSvc *services.SettlementConfigService
+	merchantSvc   *services.MerchantService
 }
 
 func NewPaymentOptionsHandler(
 	paymentSvc *services.PaymentOptionsService,
 	settlementSvc *services.SettlementConfigService,
+	merchantSvc *services.MerchantService,
 ) *PaymentOptionsHandler {
 	return &PaymentOptionsHandler{
 		paymentSvc:    paymentSvc,
 		settlementSvc: settlementSvc,
+		merchantSvc:   merchantSvc,
 	}
 }
 
@@ -85,8 +90,8 @@ func (h *PaymentOptionsHandler) UpdatePaymentOptions(c *fiber.Ctx) error {
 	})
 }
 
-// GetSettlementConfig retrieves settlement config for a merchant
-// GET /merchants/:id/settlement-config
+// GetSettlementConfig retrieves a merchant's settlement config for one of its currencies
+// GET /merchants/:id/settlement-config?currency=GHS (defaults to the merchant's default currency)
 func (h *PaymentOptionsHandler) GetSettlementConfig(c *fiber.Ctx) error {
 	merchantIDStr := c.Params("id")
 	if merchantIDStr == "" {
@@ -101,7 +106,12 @@ func (h *PaymentOptionsHandler) GetSettlementConfig(c *fiber.Ctx) error {
 		})
 	}
 
-	config, err := h.settlementSvc.GetSettlementConfig(c.Context(), merchantID)
+	currency, err := h.merchantSvc.ResolveCurrency(c.Context(), merchantID, c.Query("currency"))
+	if err != nil {
+		return currencyError(c, err)
+	}
+
+	config, err := h.settlementSvc.GetSettlementConfig(c.Context(), merchantID, currency)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -111,7 +121,8 @@ func (h *PaymentOptionsHandler) GetSettlementConfig(c *fiber.Ctx) error {
 	return c.JSON(config)
 }
 
-// UpdateSettlementConfig updates settlement config for a merchant
+// UpdateSettlementConfig updates a merchant's settlement config for the currency in the
+// body or query, defaulting to the merchant's default currency
 // PUT /merchants/:id/settlement-config
 func (h *PaymentOptionsHandler) UpdateSettlementConfig(c *fiber.Ctx) error {
 	merchantIDStr := c.Params("id")
@@ -135,6 +146,14 @@ func (h *PaymentOptionsHandler) UpdateSettlementConfig(c *fiber.Ctx) error {
 	}
 
 	req.MerchantID = merchantID
+	requested := req.Currency
+	if requested == "" {
+		requested = c.Query("currency")
+	}
+	req.Currency, err = h.merchantSvc.ResolveCurrency(c.Context(), merchantID, requested)
+	if err != nil {
+		return currencyError(c, err)
+	}
 
 	if err := h.settlementSvc.UpdateSettlementConfig(c.Context(), merchantID, &req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -155,3 +174,22 @@ func (h *PaymentOptionsHandler) Register(app *fiber.App) {
 	merchants.Get("/:id/settlement-config", h.GetSettlementConfig)
 	merchants.Put("/:id/settlement-config", h.UpdateSettlementConfig)
 }
+
+// currencyError reports a currency the merchant cannot use
+func currencyError(c *fiber.Ctx, err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repositories.ErrMerchantNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "merchant not found",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load merchant currencies",
+		})
+	}
+}
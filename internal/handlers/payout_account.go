@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+type PayoutAccountHandler struct {
+	svc *services.PayoutAccountService
+}
+
+func NewPayoutAccountHandler(svc *services.PayoutAccountService) *PayoutAccountHandler {
+	return &PayoutAccountHandler{svc: svc}
+}
+
+func (h *PayoutAccountHandler) List(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.List(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list payout accounts")
+	}
+	return c.JSON(resp)
+}
+
+// Create verifies a bank account with the bank and adds it in its cooling-off period
+func (h *PayoutAccountHandler) Create(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.PayoutAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Create(c.Context(), id, middleware.ActorFromContext(c), req)
+	if err != nil {
+		return payoutAccountError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// Update replaces an account's bank details, restarting its cooling-off period
+func (h *PayoutAccountHandler) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	accountID, err := c.ParamsInt("account_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payout account ID")
+	}
+	var req dto.PayoutAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Update(c.Context(), id, accountID, middleware.ActorFromContext(c), req)
+	if err != nil {
+		return payoutAccountError(err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *PayoutAccountHandler) SetPrimary(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	accountID, err := c.ParamsInt("account_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payout account ID")
+	}
+	resp, err := h.svc.SetPrimary(c.Context(), id, accountID, middleware.ActorFromContext(c))
+	if err != nil {
+		return payoutAccountError(err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *PayoutAccountHandler) Remove(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	accountID, err := c.ParamsInt("account_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payout account ID")
+	}
+	if err := h.svc.Remove(c.Context(), id, accountID, middleware.ActorFromContext(c)); err != nil {
+		return payoutAccountError(err)
+	}
+	return c.JSON(fiber.Map{"id": accountID, "status": "removed"})
+}
+
+// Register registers the payout account routes
+func (h *PayoutAccountHandler) Register(app *fiber.App) {
+	merchants := app.Group("/merchants")
+	merchants.Get("/:id/payout-accounts", h.List)
+	merchants.Post("/:id/payout-accounts", h.Create)
+	merchants.Put("/:id/payout-accounts/:account_id", h.Update)
+	merchants.Post("/:id/payout-accounts/:account_id/primary", h.SetPrimary)
+	merchants.Delete("/:id/payout-accounts/:account_id", h.Remove)
+}
+
+func payoutAccountError(err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, repositories.ErrPayoutAccountNotFound), errors.Is(err, repositories.ErrMerchantNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repositories.ErrPayoutAccountExists), errors.Is(err, repositories.ErrPrimaryPayoutAccount),
+		errors.Is(err, services.ErrKYCReviewPending):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrBankResolverUnavailable):
+		return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+	default:
+		log.Printf("ERROR: payout account operation failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "payout account operation failed")
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+type TeamHandler struct {
+	svc *services.TeamService
+}
+
+func NewTeamHandler(svc *services.TeamService) *TeamHandler {
+	return &TeamHandler{svc: svc}
+}
+
+// List returns a merchant's members and pending invitations
+func (h *TeamHandler) List(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.List(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list team members")
+	}
+	return c.JSON(resp)
+}
+
+// Invite creates an invitation; the returned token is shown only once
+func (h *TeamHandler) Invite(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.TeamInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Invite(c.Context(), id, teamActor(c), middleware.ActorFromContext(c), req)
+	if err != nil {
+		return teamError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *TeamHandler) UpdateRole(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	memberID, err := c.ParamsInt("member_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid member ID")
+	}
+	var req dto.TeamRoleUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.UpdateRole(c.Context(), id, memberID, teamActor(c), req)
+	if err != nil {
+		return teamError(err)
+	}
+	return c.JSON(resp)
+}
+
+// Revoke removes a member or cancels a pending invitation
+func (h *TeamHandler) Revoke(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	memberID, err := c.ParamsInt("member_id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid member ID")
+	}
+	resp, err := h.svc.Revoke(c.Context(), id, memberID, teamActor(c))
+	if err != nil {
+		return teamError(err)
+	}
+	return c.JSON(resp)
+}
+
+// AcceptInvitation is called by the auth service once the invitee has signed in
+func (h *TeamHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req dto.TeamInvitationAcceptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.AcceptInvitation(c.Context(), req)
+	if err != nil {
+		return teamError(err)
+	}
+	log.Printf("Team invitation %d accepted for merchant %d via %s", resp.ID, resp.MerchantID, middleware.ServiceCallerFromContext(c))
+	return c.JSON(resp)
+}
+
+// Memberships lists the merchants a user may act on, so the auth service can issue sessions
+func (h *TeamHandler) Memberships(c *fiber.Ctx) error {
+	userID := strings.TrimSpace(c.Query("user_id"))
+	if userID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "user_id is required")
+	}
+	resp, err := h.svc.Memberships(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list memberships")
+	}
+	return c.JSON(resp)
+}
+
+// Register registers the team member routes
+func (h *TeamHandler) Register(app *fiber.App) {
+	merchants := app.Group("/merchants")
+	merchants.Get("/:id/team", h.List)
+	merchants.Post("/:id/team/invitations", h.Invite)
+	merchants.Put("/:id/team/:member_id/role", h.UpdateRole)
+	merchants.Delete("/:id/team/:member_id", h.Revoke)
+
+	app.Post("/internal/team/invitations/accept", h.AcceptInvitation)
+	app.Get("/internal/team/memberships", h.Memberships)
+}
+
+// teamActor returns the member making a dashboard request, or nil for admins and API keys
+func teamActor(c *fiber.Ctx) *models.TeamMember {
+	member, _ := middleware.TeamMemberFromContext(c)
+	return member
+}
+
+func teamError(err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrOwnerRoleRequired):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, repositories.ErrTeamMemberNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repositories.ErrInvitationInvalid):
+		return fiber.NewError(fiber.StatusGone, err.Error())
+	case errors.Is(err, repositories.ErrTeamMemberExists), errors.Is(err, repositories.ErrLastOwner):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		log.Printf("ERROR: team operation failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "team operation failed")
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+type TwoFactorHandler struct {
+	svc *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(svc *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{svc: svc}
+}
+
+func (h *TwoFactorHandler) Status(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.Status(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to load two-factor status")
+	}
+	return c.JSON(resp)
+}
+
+// Enroll returns a new TOTP secret to add to an authenticator app
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	resp, err := h.svc.Enroll(c.Context(), id)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// Activate turns on two-factor confirmation once the first code checks out
+func (h *TwoFactorHandler) Activate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Activate(c.Context(), id, req.Code)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	return c.JSON(resp)
+}
+
+// Confirm exchanges a TOTP or recovery code for a short-lived confirmation token
+func (h *TwoFactorHandler) Confirm(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.TwoFactorConfirmRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	resp, err := h.svc.Confirm(c.Context(), id, req)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid merchant ID")
+	}
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	if err := h.svc.Disable(c.Context(), id, req); err != nil {
+		return twoFactorError(err)
+	}
+	return c.JSON(fiber.Map{"enabled": false})
+}
+
+// Register registers the two-factor routes
+func (h *TwoFactorHandler) Register(app *fiber.App) {
+	merchants := app.Group("/merchants")
+	merchants.Get("/:id/2fa", h.Status)
+	merchants.Post("/:id/2fa/enroll", h.Enroll)
+	merchants.Post("/:id/2fa/activate", h.Activate)
+	merchants.Post("/:id/2fa/confirm", h.Confirm)
+	merchants.Post("/:id/2fa/disable", h.Disable)
+}
+
+func twoFactorError(err error) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, repositories.ErrTwoFactorNotFound), errors.Is(err, repositories.ErrMerchantNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTwoFactorAlreadySetUp):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrTwoFactorLocked):
+		return fiber.NewError(fiber.StatusTooManyRequests, err.Error())
+	case errors.Is(err, services.ErrTwoFactorUnavailable):
+		return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+	default:
+		log.Printf("ERROR: two-factor operation failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "two-factor operation failed")
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+// Context keys populated by AdminAuthMiddleware
+const (
+	LocalAdmin      = "admin"
+	LocalAdminID    = "admin_id"
+	AuthMethodAdmin = "admin"
+)
+
+// AdminAuthMiddleware authenticates back-office admins carrying an adm_ token
+type AdminAuthMiddleware struct {
+	adminService *services.AdminService
+}
+
+func NewAdminAuthMiddleware(adminService *services.AdminService) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{adminService: adminService}
+}
+
+// Authenticate verifies a Bearer adm_ token when one is presented and stores the
+// admin in the request context. Requests without an admin token pass through.
+func (m *AdminAuthMiddleware) Authenticate(c *fiber.Ctx) error {
+	token := bearerToken(c)
+	if token == "" || !models.LooksLikeAdminToken(token) {
+		return c.Next()
+	}
+
+	admin, err := m.adminService.Authenticate(c.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAdminInactive):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "admin_inactive",
+				"message": "This admin account has been disabled.",
+			})
+		case errors.Is(err, services.ErrInvalidAdminToken):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "invalid_admin_token",
+				"message": "The admin token provided is not valid.",
+			})
+		default:
+			log.Printf("ERROR: admin authentication failed: %v", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to authenticate admin")
+		}
+	}
+
+	c.Locals(LocalAdmin, admin)
+	c.Locals(LocalAdminID, admin.ID)
+	c.Locals(LocalAuthMethod, AuthMethodAdmin)
+	return c.Next()
+}
+
+// RequireRole rejects requests that were not made by an admin holding one of roles.
+// Super admins pass every role check.
+func RequireRole(roles ...models.AdminRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		admin, ok := AdminFromContext(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "admin_required",
+				"message": "Provide an admin token as a Bearer token in the Authorization header.",
+			})
+		}
+		if !admin.HasRole(roles...) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          "insufficient_role",
+				"message":        "Your admin account is not permitted to perform this operation.",
+				"required_roles": roles,
+			})
+		}
+		return c.Next()
+	}
+}
+
+// AdminFromContext returns the authenticated admin, if any
+func AdminFromContext(c *fiber.Ctx) (*models.Admin, bool) {
+	admin, ok := c.Locals(LocalAdmin).(*models.Admin)
+	return admin, ok
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+// Context keys populated by APIKeyAuthMiddleware
+const (
+	LocalMerchantID    = "merchant_id"
+	LocalAPIKey        = "api_key"
+	LocalAPIKeyID      = "api_key_id"
+	LocalAPIKeyType    = "api_key_type"
+	LocalAPIKeyEnv     = "api_key_environment"
+	LocalAuthMethod    = "auth_method"
+	AuthMethodAPIKey   = "api_key"
+	bearerSchemePrefix = "bearer "
+)
+
+// APIKeyAuthMiddleware authenticates requests carrying a merchant pk_/sk_ API key
+type APIKeyAuthMiddleware struct {
+	apiKeyService *services.APIKeyService
+	usage         *services.APIKeyUsageRecorder
+}
+
+func NewAPIKeyAuthMiddleware(apiKeyService *services.APIKeyService, usage *services.APIKeyUsageRecorder) *APIKeyAuthMiddleware {
+	return &APIKeyAuthMiddleware{
+		apiKeyService: apiKeyService,
+		usage:         usage,
+	}
+}
+
+// Authenticate verifies a Bearer pk_/sk_ key when one is presented and stores the
+// resolved merchant in the request context. Requests without an API key pass through.
+func (m *APIKeyAuthMiddleware) Authenticate(c *fiber.Ctx) error {
+	token := bearerToken(c)
+	if token == "" || !models.LooksLikeAPIKey(token) {
+		return c.Next()
+	}
+
+	key, err := m.apiKeyService.Authenticate(c.Context(), token, ClientIP(c))
+	if err != nil {
+		var ipErr *services.IPNotAllowedError
+		switch {
+		case errors.As(err, &ipErr):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":      "ip_not_allowed",
+				"message":    fmt.Sprintf("Requests from %s are not permitted for this API key. Add the address to the key's IP allowlist.", ipErr.IP),
+				"blocked_ip": ipErr.IP,
+			})
+		case errors.Is(err, services.ErrAPIKeyInactive):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "api_key_inactive",
+				"message": "This API key has been deactivated. Use your current key from the dashboard.",
+			})
+		case errors.Is(err, services.ErrAPIKeyExpired):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "api_key_expired",
+				"message": "This API key has expired. Use your current key from the dashboard.",
+			})
+		case errors.Is(err, services.ErrLiveKeyNotPermitted):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "live_key_not_permitted",
+				"message": "Live API keys can only be used once your KYC is approved and your account is active.",
+			})
+		case errors.Is(err, services.ErrInvalidAPIKey):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "invalid_api_key",
+				"message": "The API key provided is not valid.",
+			})
+		default:
+			log.Printf("ERROR: API key authentication failed: %v", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to authenticate api key")
+		}
+	}
+
+	// merchant_id is stored as a string to match what KYCCheckMiddleware expects
+	c.Locals(LocalMerchantID, strconv.Itoa(key.MerchantID))
+	c.Locals(LocalAPIKey, key)
+	c.Locals(LocalAPIKeyID, key.ID)
+	c.Locals(LocalAPIKeyType, key.KeyType)
+	c.Locals(LocalAPIKeyEnv, key.Environment)
+	c.Locals(LocalAuthMethod, AuthMethodAPIKey)
+
+	err = c.Next()
+	if m.usage != nil {
+		m.usage.Record(key.ID, ClientIP(c), requestFailed(c, err))
+	}
+	return err
+}
+
+// requestFailed reports whether the downstream handlers produced an error response
+func requestFailed(c *fiber.Ctx, err error) bool {
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return fiberErr.Code >= fiber.StatusBadRequest
+		}
+		return true
+	}
+	return c.Response().StatusCode() >= fiber.StatusBadRequest
+}
+
+// MerchantIDFromContext returns the authenticated merchant ID, if any
+func MerchantIDFromContext(c *fiber.Ctx) (int, bool) {
+	merchantIDStr, ok := c.Locals(LocalMerchantID).(string)
+	if !ok || merchantIDStr == "" {
+		return 0, false
+	}
+	id, err := strconv.Atoi(merchantIDStr)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// ActorFromContext describes the authenticated caller for audit fields such as revoked_by
+func ActorFromContext(c *fiber.Ctx) string {
+	if admin, ok := AdminFromContext(c); ok {
+		return fmt.Sprintf("admin:%d", admin.ID)
+	}
+	if keyID, ok := c.Locals(LocalAPIKeyID).(int); ok {
+		return fmt.Sprintf("api_key:%d", keyID)
+	}
+	if subject, ok := c.Locals(LocalSessionSubject).(string); ok && subject != "" {
+		return "user:" + subject
+	}
+	return "anonymous"
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	header := strings.TrimSpace(c.Get(fiber.HeaderAuthorization))
+	if len(header) <= len(bearerSchemePrefix) || !strings.EqualFold(header[:len(bearerSchemePrefix)], bearerSchemePrefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(bearerSchemePrefix):])
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+const localScopeChecked = "api_key_scope_checked"
+
+// RequireScope rejects API key requests whose key does not hold scope.
+// Requests that were not authenticated with an API key are left to other checks.
+func RequireScope(scope models.APIKeyScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, ok := c.Locals(LocalAPIKey).(*models.APIKey)
+		if !ok {
+			return c.Next()
+		}
+		if !key.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          "insufficient_scope",
+				"message":        "This API key is not permitted to perform this operation.",
+				"required_scope": scope,
+			})
+		}
+		c.Locals(localScopeChecked, true)
+		return c.Next()
+	}
+}
+
+// RestrictUnscopedKeys denies publishable and restricted keys on any route that no
+// RequireScope guard has approved. It must be registered after the scope guards.
+func RestrictUnscopedKeys(c *fiber.Ctx) error {
+	key, ok := c.Locals(LocalAPIKey).(*models.APIKey)
+	if !ok || key.KeyType == models.APIKeyTypeSecret {
+		return c.Next()
+	}
+	if checked, _ := c.Locals(localScopeChecked).(bool); checked {
+		return c.Next()
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   "insufficient_scope",
+		"message": "This API key is not permitted to perform this operation.",
+	})
+}
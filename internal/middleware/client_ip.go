@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalClientIP holds the client IP resolved by ClientIPResolver
+const LocalClientIP = "client_ip"
+
+// ClientIPResolver works out the client IP behind trusted proxies. Forwarded headers are
+// only read when the connection comes from a trusted proxy, and then from the right: the
+// first hop that is not a trusted proxy is the client. Entries to the left of it were
+// written by the client and cannot be trusted.
+type ClientIPResolver struct {
+	header  string
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver builds a resolver for header, trusting the given IPs and CIDR
+// ranges. A header without trusted proxies would let any caller pick its own IP, so
+// it is rejected.
+func NewClientIPResolver(header string, trustedProxies []string) (*ClientIPResolver, error) {
+	if header != "" && len(trustedProxies) == 0 {
+		return nil, fmt.Errorf("a proxy header is set but no trusted proxies are configured")
+	}
+	r := &ClientIPResolver{header: header}
+	for _, entry := range trustedProxies {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.trusted = append(r.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// Handle stores the resolved client IP for ClientIP
+func (r *ClientIPResolver) Handle(c *fiber.Ctx) error {
+	c.Locals(LocalClientIP, r.resolve(c.Context().RemoteIP(), c.Get(r.header)))
+	return c.Next()
+}
+
+func (r *ClientIPResolver) resolve(remote net.IP, forwarded string) string {
+	if r.header == "" || forwarded == "" || !r.isTrusted(remote) {
+		return remote.String()
+	}
+	hops := strings.Split(forwarded, ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// A malformed hop means nothing further left can be trusted
+			break
+		}
+		client = ip
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (r *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client IP resolved by ClientIPResolver, falling back to the
+// connection's remote address
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(LocalClientIP).(string); ok {
+		return ip
+	}
+	return c.Context().RemoteIP().String()
+}
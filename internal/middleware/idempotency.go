@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+const (
+	HeaderIdempotencyKey    = "Idempotency-Key"
+	HeaderIdempotentReplay  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency replays the stored response when a request is retried with the same
+// Idempotency-Key header. Keys are scoped to scope and forgotten after ttl. Server
+// errors are not stored, so a request that failed that way can be retried.
+func Idempotency(repo *repositories.IdempotencyRepository, scope string, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "invalid_idempotency_key",
+				"message": "Idempotency-Key must be at most 255 characters.",
+			})
+		}
+
+		sum := sha256.Sum256(c.Body())
+		requestHash := hex.EncodeToString(sum[:])
+
+		existing, reserved, err := repo.Reserve(c.Context(), scope, key, requestHash, ttl)
+		if err != nil {
+			log.Printf("ERROR: failed to reserve idempotency key: %v", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to process idempotency key")
+		}
+		if !reserved {
+			switch {
+			case existing.RequestHash != requestHash:
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"error":   "idempotency_key_reused",
+					"message": "This Idempotency-Key was already used with a different request body.",
+				})
+			case existing.ResponseStatus == 0:
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error":   "request_in_progress",
+					"message": "A request with this Idempotency-Key is still being processed.",
+				})
+			default:
+				c.Set(HeaderIdempotentReplay, "true")
+				c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+				return c.Status(existing.ResponseStatus).Send(existing.ResponseBody)
+			}
+		}
+
+		err = c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			if releaseErr := repo.Release(c.Context(), scope, key); releaseErr != nil {
+				log.Printf("ERROR: failed to release idempotency key: %v", releaseErr)
+			}
+			return err
+		}
+		if err := repo.Complete(c.Context(), scope, key, status, c.Response().Body()); err != nil {
+			log.Printf("ERROR: failed to store idempotent response: %v", err)
+		}
+		return nil
+	}
+}
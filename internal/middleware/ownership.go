@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+// RequirePrincipal rejects requests made without a merchant identity (API key or
+// dashboard session) or an admin token
+func RequirePrincipal(c *fiber.Ctx) error {
+	if _, ok := AdminFromContext(c); ok {
+		return c.Next()
+	}
+	if _, ok := MerchantIDFromContext(c); ok {
+		return c.Next()
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error":   "authentication_required",
+		"message": "Authenticate with an API key, a dashboard session or an admin token.",
+	})
+}
+
+// RequireMerchant only admits the merchant itself, through an API key or a dashboard
+// session. It guards responses such as live key secrets that admins must never see.
+func RequireMerchant(c *fiber.Ctx) error {
+	if _, ok := MerchantIDFromContext(c); ok {
+		return c.Next()
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   "merchant_required",
+		"message": "Only the merchant can perform this operation.",
+	})
+}
+
+// LocalParentAccessAllowed marks a route marketplace parents may call for their sub-merchants
+const LocalParentAccessAllowed = "parent_access_allowed"
+
+// AllowParentAccess lets a marketplace parent call the route for its sub-merchants. It must
+// be registered ahead of RequireMerchantAccess; other routes are limited to the merchant itself.
+func AllowParentAccess(c *fiber.Ctx) error {
+	c.Locals(LocalParentAccessAllowed, true)
+	return c.Next()
+}
+
+const localAdminWriteRoles = "admin_write_roles"
+
+// AllowAdminWrite lets admins holding one of roles call a merchant-scoped write route. It
+// must be registered ahead of RequireMerchantAccess. Admins may read any merchant, but
+// writes on routes without an allow-list are limited to the merchant itself.
+func AllowAdminWrite(roles ...models.AdminRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localAdminWriteRoles, roles)
+		return c.Next()
+	}
+}
+
+// MerchantAccess decides which merchants an authenticated principal may act on. A
+// merchant may act on itself and on its marketplace sub-merchants. Admins may read any
+// merchant and write where the route's AllowAdminWrite list admits one of their roles.
+type MerchantAccess struct {
+	merchants *services.MerchantService
+}
+
+func NewMerchantAccess(merchants *services.MerchantService) *MerchantAccess {
+	return &MerchantAccess{merchants: merchants}
+}
+
+// RequireMerchantAccess rejects requests for a merchant the caller may not act on.
+// The merchant is named by the route parameter param. Parents only reach their
+// sub-merchants on routes marked with AllowParentAccess. Foreign merchants get a 404 so
+// IDs cannot be probed.
+func (a *MerchantAccess) RequireMerchantAccess(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if admin, ok := AdminFromContext(c); ok {
+			if !adminMayAccess(c, admin) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":   "insufficient_role",
+					"message": "Your admin account is not permitted to perform this operation.",
+				})
+			}
+			return c.Next()
+		}
+		if _, ok := MerchantIDFromContext(c); !ok {
+			return RequirePrincipal(c)
+		}
+		parentAllowed, _ := c.Locals(LocalParentAccessAllowed).(bool)
+		merchantID, err := strconv.Atoi(c.Params(param))
+		if err != nil || !a.canAccess(c, merchantID, parentAllowed) {
+			return NotFound(c)
+		}
+		return c.Next()
+	}
+}
+
+// CanAccessMerchant reports whether the authenticated principal may act on merchantID,
+// counting a marketplace parent as allowed. Handlers use it for resources such as
+// payment links and KYC submissions that parents manage for their sub-merchants.
+func (a *MerchantAccess) CanAccessMerchant(c *fiber.Ctx, merchantID int) bool {
+	return a.canAccess(c, merchantID, true)
+}
+
+func (a *MerchantAccess) canAccess(c *fiber.Ctx, merchantID int, parentAllowed bool) bool {
+	if admin, ok := AdminFromContext(c); ok {
+		return adminMayAccess(c, admin)
+	}
+	authMerchantID, ok := MerchantIDFromContext(c)
+	if !ok {
+		return false
+	}
+	if authMerchantID == merchantID {
+		return true
+	}
+	if !parentAllowed {
+		return false
+	}
+	isParent, err := a.merchants.IsParentOf(c.Context(), authMerchantID, merchantID)
+	if err != nil {
+		log.Printf("ERROR: failed to check merchant %d hierarchy for %d: %v", merchantID, authMerchantID, err)
+		return false
+	}
+	return isParent
+}
+
+// adminMayAccess admits admins to reads and to writes allowed by AllowAdminWrite
+func adminMayAccess(c *fiber.Ctx, admin *models.Admin) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead:
+		return true
+	}
+	roles, ok := c.Locals(localAdminWriteRoles).([]models.AdminRole)
+	return ok && admin.HasRole(roles...)
+}
+
+// NotFound writes the response used for resources the caller may not see
+func NotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error":   "not_found",
+		"message": "The requested resource was not found.",
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/auth"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+// Context keys populated by SessionAuthMiddleware
+const (
+	LocalSessionSubject = "session_subject"
+	AuthMethodSession   = "session"
+)
+
+// SessionAuthMiddleware authenticates dashboard requests carrying a JWT issued by the auth service
+type SessionAuthMiddleware struct {
+	verifier *auth.SessionVerifier
+	team     *services.TeamService
+}
+
+func NewSessionAuthMiddleware(verifier *auth.SessionVerifier, team *services.TeamService) *SessionAuthMiddleware {
+	return &SessionAuthMiddleware{verifier: verifier, team: team}
+}
+
+// Authenticate verifies a Bearer JWT when one is presented and stores the merchant it
+// was issued for in the request context, provided the user is still on that merchant's
+// team. API keys and admin tokens are left to their own middleware; requests without a
+// token pass through.
+func (m *SessionAuthMiddleware) Authenticate(c *fiber.Ctx) error {
+	token := bearerToken(c)
+	if token == "" || models.LooksLikeAPIKey(token) || models.LooksLikeAdminToken(token) || strings.Count(token, ".") != 2 {
+		return c.Next()
+	}
+
+	session, err := m.verifier.Verify(c.Context(), token)
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidSession) {
+			log.Printf("ERROR: session verification failed: %v", err)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "invalid_session",
+			"message": "Your session is invalid or has expired. Sign in again.",
+		})
+	}
+
+	member, err := m.team.ResolveSession(c.Context(), session.MerchantID, session.Subject)
+	if err != nil {
+		if errors.Is(err, services.ErrNotTeamMember) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "not_a_team_member",
+				"message": "You are not a member of this merchant's team.",
+			})
+		}
+		log.Printf("ERROR: team membership lookup failed: %v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to verify team membership")
+	}
+
+	// merchant_id is stored as a string to match what KYCCheckMiddleware expects
+	c.Locals(LocalMerchantID, strconv.Itoa(session.MerchantID))
+	c.Locals(LocalSessionSubject, session.Subject)
+	c.Locals(LocalTeamMember, member)
+	c.Locals(LocalAuthMethod, AuthMethodSession)
+	return c.Next()
+}
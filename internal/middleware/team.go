@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+// LocalTeamMember holds the *models.TeamMember behind a dashboard session
+const LocalTeamMember = "team_member"
+
+// RequireTeamRole limits a route to team members holding one of roles. Owners pass
+// every check. Only dashboard sessions are checked: API keys are governed by scopes
+// and admins by admin roles.
+func RequireTeamRole(roles ...models.TeamRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals(LocalAuthMethod) != AuthMethodSession {
+			return c.Next()
+		}
+		member, ok := TeamMemberFromContext(c)
+		if !ok || !member.HasRole(roles...) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          "insufficient_team_role",
+				"message":        "Your role on this team is not permitted to perform this operation.",
+				"required_roles": roles,
+			})
+		}
+		return c.Next()
+	}
+}
+
+// TeamMemberFromContext returns the team member behind a dashboard session, if any
+func TeamMemberFromContext(c *fiber.Ctx) (*models.TeamMember, bool) {
+	member, ok := c.Locals(LocalTeamMember).(*models.TeamMember)
+	return member, ok
+}
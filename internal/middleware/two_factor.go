@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/services"
+)
+
+// HeaderConfirmationToken carries the token issued by POST /merchants/:id/2fa/confirm
+const HeaderConfirmationToken = "X-Confirmation-Token"
+
+// RequireConfirmation guards a sensitive action. Once the merchant named by the route
+// parameter param has enabled two-factor authentication, the merchant's own requests,
+// whether made with a dashboard session or an API key, must carry an unused confirmation
+// token issued for action. Admins are not prompted; the routes they may write are
+// limited by role.
+func RequireConfirmation(svc *services.TwoFactorService, param string, action models.ConfirmationAction) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := MerchantIDFromContext(c); !ok {
+			return c.Next()
+		}
+		merchantID, err := strconv.Atoi(c.Params(param))
+		if err != nil {
+			return NotFound(c)
+		}
+
+		err = svc.RequireConfirmation(c.Context(), merchantID, action, c.Get(HeaderConfirmationToken))
+		switch {
+		case err == nil:
+			return c.Next()
+		case errors.Is(err, services.ErrConfirmationRequired):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "confirmation_required",
+				"message": "Confirm this action with your authenticator app and retry with the X-Confirmation-Token header.",
+				"action":  action,
+			})
+		case errors.Is(err, services.ErrTwoFactorUnavailable):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error":   "two_factor_unavailable",
+				"message": "Two-factor confirmation is temporarily unavailable.",
+			})
+		default:
+			log.Printf("ERROR: two-factor confirmation check failed: %v", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to check two-factor confirmation")
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// AdminRole names a back-office permission set
+type AdminRole string
+
+const (
+	AdminRoleKYCReviewer AdminRole = "kyc_reviewer"
+	AdminRoleOps         AdminRole = "ops"
+	AdminRoleFinance     AdminRole = "finance"
+	// AdminRoleSuperAdmin may perform every admin operation, including managing admins
+	AdminRoleSuperAdmin AdminRole = "super_admin"
+)
+
+// AllAdminRoles lists every role an admin may hold
+var AllAdminRoles = []AdminRole{
+	AdminRoleKYCReviewer,
+	AdminRoleOps,
+	AdminRoleFinance,
+	AdminRoleSuperAdmin,
+}
+
+// IsValidAdminRole reports whether role is a known role
+func IsValidAdminRole(role AdminRole) bool {
+	for _, r := range AllAdminRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// adminTokenPrefix marks admin bearer tokens so they are never mistaken for merchant API keys
+const adminTokenPrefix = "adm_"
+
+// Admin is a back-office user authenticated with an adm_ bearer token
+type Admin struct {
+	ID          int       `json:"id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	Roles       []string  `json:"roles"`
+	TokenHash   string    `json:"-"` // Never expose the hash
+	TokenPrefix string    `json:"token_prefix"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// HasRole reports whether the admin holds any of roles. Super admins hold every role.
+func (a *Admin) HasRole(roles ...AdminRole) bool {
+	for _, held := range a.Roles {
+		if held == string(AdminRoleSuperAdmin) {
+			return true
+		}
+		for _, role := range roles {
+			if held == string(role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GenerateAdminToken creates a new adm_ token for admin, hashed for storage with hasher
+func GenerateAdminToken(admin *Admin, hasher *APIKeyHasher) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := adminTokenPrefix + base64.URLEncoding.EncodeToString(tokenBytes)
+
+	admin.TokenHash = hasher.Hash(token)
+	admin.TokenPrefix = token[:APIKeyPrefixLength]
+	return token, nil
+}
+
+// LooksLikeAdminToken reports whether the value carries the adm_ token prefix
+func LooksLikeAdminToken(value string) bool {
+	return strings.HasPrefix(value, adminTokenPrefix)
+}
@@ -2,9 +2,10 @@ package models
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 )
 
@@ -13,8 +14,62 @@ type APIKeyType string
 const (
 	APIKeyTypePublic APIKeyType = "public"
 	APIKeyTypeSecret APIKeyType = "secret"
+	// APIKeyTypeRestricted keys (rk_) can only call endpoints covered by their scopes
+	APIKeyTypeRestricted APIKeyType = "restricted"
 )
 
+// APIKeyScope names a permission that can be granted to a restricted key
+type APIKeyScope string
+
+const (
+	ScopeMerchantRead          APIKeyScope = "merchant:read"
+	ScopePaymentLinksRead      APIKeyScope = "payment_links:read"
+	ScopePaymentLinksWrite     APIKeyScope = "payment_links:write"
+	ScopeBalanceRead           APIKeyScope = "balance:read"
+	ScopeKYCRead               APIKeyScope = "kyc:read"
+	ScopeKYCWrite              APIKeyScope = "kyc:write"
+	ScopePaymentOptionsRead    APIKeyScope = "payment_options:read"
+	ScopePaymentOptionsWrite   APIKeyScope = "payment_options:write"
+	ScopeSettlementConfigRead  APIKeyScope = "settlement_config:read"
+	ScopeSettlementConfigWrite APIKeyScope = "settlement_config:write"
+	ScopeSubMerchantsRead      APIKeyScope = "sub_merchants:read"
+	ScopeSubMerchantsWrite     APIKeyScope = "sub_merchants:write"
+	ScopePayoutAccountsRead    APIKeyScope = "payout_accounts:read"
+)
+
+// AllAPIKeyScopes lists every scope a restricted key may carry
+var AllAPIKeyScopes = []APIKeyScope{
+	ScopeMerchantRead,
+	ScopePaymentLinksRead,
+	ScopePaymentLinksWrite,
+	ScopeBalanceRead,
+	ScopeKYCRead,
+	ScopeKYCWrite,
+	ScopePaymentOptionsRead,
+	ScopePaymentOptionsWrite,
+	ScopeSettlementConfigRead,
+	ScopeSettlementConfigWrite,
+	ScopeSubMerchantsRead,
+	ScopeSubMerchantsWrite,
+	ScopePayoutAccountsRead,
+}
+
+// publicKeyScopes are the read-only operations a publishable key may perform from a browser
+var publicKeyScopes = []APIKeyScope{
+	ScopeMerchantRead,
+	ScopePaymentLinksRead,
+}
+
+// IsValidAPIKeyScope reports whether scope is a known scope
+func IsValidAPIKeyScope(scope APIKeyScope) bool {
+	for _, s := range AllAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 type Environment string
 
 const (
@@ -22,21 +77,31 @@ const (
 	EnvironmentLive Environment = "live"
 )
 
+// APIKeyPrefixLength is the number of leading characters stored in KeyPrefix
+const APIKeyPrefixLength = 16
+
 type APIKey struct {
-	ID          int         `json:"id"`
-	MerchantID  int         `json:"merchant_id"`
-	KeyHash     string      `json:"-"` // Never expose the hash
-	KeyPrefix   string      `json:"key_prefix"`
-	KeyType     APIKeyType  `json:"key_type"`
-	Environment Environment `json:"environment"`
-	IsActive    bool        `json:"is_active"`
-	LastUsedAt  *time.Time  `json:"last_used_at,omitempty"`
-	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
-	CreatedAt   time.Time   `json:"created_at"`
+	ID           int         `json:"id"`
+	MerchantID   int         `json:"merchant_id"`
+	KeyHash      string      `json:"-"` // Never expose the hash
+	KeyPrefix    string      `json:"key_prefix"`
+	KeyType      APIKeyType  `json:"key_type"`
+	Environment  Environment `json:"environment"`
+	Scopes       []string    `json:"scopes,omitempty"`      // Only set for restricted keys
+	AllowedIPs   []string    `json:"allowed_ips,omitempty"` // CIDR ranges; empty means any IP
+	IsActive     bool        `json:"is_active"`
+	LastUsedAt   *time.Time  `json:"last_used_at,omitempty"`
+	LastUsedIP   *string     `json:"last_used_ip,omitempty"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time  `json:"revoked_at,omitempty"`
+	RevokedBy    *string     `json:"revoked_by,omitempty"`
+	RevokeReason *string     `json:"revoke_reason,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
 }
 
-// GenerateAPIKey creates a new API key with the given prefix (pk_ or sk_)
-func GenerateAPIKey(merchantID int, keyType APIKeyType, env Environment) (*APIKey, string, error) {
+// GenerateAPIKey creates a new API key with the given prefix (pk_, sk_ or rk_),
+// hashed for storage with hasher
+func GenerateAPIKey(merchantID int, keyType APIKeyType, env Environment, hasher *APIKeyHasher) (*APIKey, string, error) {
 	// Generate random bytes
 	keyBytes := make([]byte, 32)
 	if _, err := rand.Read(keyBytes); err != nil {
@@ -48,9 +113,12 @@ func GenerateAPIKey(merchantID int, keyType APIKeyType, env Environment) (*APIKe
 
 	// Determine prefix
 	var prefix string
-	if keyType == APIKeyTypePublic {
+	switch keyType {
+	case APIKeyTypePublic:
 		prefix = "pk_"
-	} else {
+	case APIKeyTypeRestricted:
+		prefix = "rk_"
+	default:
 		prefix = "sk_"
 	}
 
@@ -64,13 +132,12 @@ func GenerateAPIKey(merchantID int, keyType APIKeyType, env Environment) (*APIKe
 	fullKey := prefix + keySecret
 
 	// Hash for storage
-	hash := sha256.Sum256([]byte(fullKey))
-	keyHash := fmt.Sprintf("%x", hash)
+	keyHash := hasher.Hash(fullKey)
 
 	// Get key prefix for identification (first 16 chars)
 	keyPrefix := fullKey
-	if len(fullKey) > 16 {
-		keyPrefix = fullKey[:16]
+	if len(fullKey) > APIKeyPrefixLength {
+		keyPrefix = fullKey[:APIKeyPrefixLength]
 	}
 
 	apiKey := &APIKey{
@@ -85,3 +152,79 @@ func GenerateAPIKey(merchantID int, keyType APIKeyType, env Environment) (*APIKe
 
 	return apiKey, fullKey, nil
 }
+
+// LooksLikeAPIKey reports whether the value carries a pk_/sk_/rk_ key prefix
+func LooksLikeAPIKey(value string) bool {
+	return strings.HasPrefix(value, "pk_") || strings.HasPrefix(value, "sk_") || strings.HasPrefix(value, "rk_")
+}
+
+// HasScope reports whether the key may perform an operation guarded by scope.
+// Secret keys hold every scope, publishable keys a fixed read-only set, and
+// restricted keys exactly the scopes they were created with.
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	var granted []string
+	switch k.KeyType {
+	case APIKeyTypeSecret:
+		return true
+	case APIKeyTypePublic:
+		for _, s := range publicKeyScopes {
+			granted = append(granted, string(s))
+		}
+	default:
+		granted = k.Scopes
+	}
+	for _, s := range granted {
+		if s == string(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the key has passed its expiry time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// SupportsIPAllowlist reports whether the key type can be pinned to IP ranges.
+// Publishable keys are used from browsers and are never IP restricted.
+func (k *APIKey) SupportsIPAllowlist() bool {
+	return k.KeyType != APIKeyTypePublic
+}
+
+// AllowsIP reports whether a request from ip may use the key
+func (k *APIKey) AllowsIP(ip string) bool {
+	if !k.SupportsIPAllowlist() || len(k.AllowedIPs) == 0 {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, cidr := range k.AllowedIPs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeCIDR accepts a CIDR range or a bare IP address and returns it in CIDR form
+func NormalizeCIDR(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP or CIDR range: %s", value)
+	}
+	return network.String(), nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// hmacHashScheme marks key hashes produced with a server-side pepper.
+// Stored format: hmac-sha256$<pepper version>$<hex digest>
+const hmacHashScheme = "hmac-sha256"
+
+// APIKeyHasher hashes API keys with HMAC-SHA256 keyed by a versioned server-side
+// pepper, so a database dump alone is not enough to test guessed keys.
+// Older pepper versions are kept for verification until every key is rehashed.
+type APIKeyHasher struct {
+	peppers map[string][]byte
+	current string
+}
+
+// NewAPIKeyHasher builds a hasher from version->pepper pairs. With no peppers it
+// falls back to the legacy unsalted SHA-256 hash, which is only fit for local use.
+func NewAPIKeyHasher(peppers map[string]string, currentVersion string) (*APIKeyHasher, error) {
+	h := &APIKeyHasher{peppers: make(map[string][]byte, len(peppers))}
+	for version, pepper := range peppers {
+		if version == "" || strings.Contains(version, "$") {
+			return nil, fmt.Errorf("invalid api key pepper version %q", version)
+		}
+		if pepper == "" {
+			return nil, fmt.Errorf("api key pepper %q is empty", version)
+		}
+		h.peppers[version] = []byte(pepper)
+	}
+	if len(h.peppers) == 0 {
+		return h, nil
+	}
+	if _, ok := h.peppers[currentVersion]; !ok {
+		return nil, fmt.Errorf("current api key pepper version %q is not configured", currentVersion)
+	}
+	h.current = currentVersion
+	return h, nil
+}
+
+// Peppered reports whether new hashes use a pepper
+func (h *APIKeyHasher) Peppered() bool {
+	return h != nil && h.current != ""
+}
+
+// Hash returns the value to store for a full API key
+func (h *APIKeyHasher) Hash(fullKey string) string {
+	if !h.Peppered() {
+		return legacyAPIKeyHash(fullKey)
+	}
+	return fmt.Sprintf("%s$%s$%s", hmacHashScheme, h.current, h.digest(h.peppers[h.current], fullKey))
+}
+
+// Verify checks fullKey against a stored hash in constant time. needsRehash is
+// true when the key matched but was stored with a legacy hash or an older pepper.
+func (h *APIKeyHasher) Verify(fullKey, stored string) (ok bool, needsRehash bool) {
+	parts := strings.Split(stored, "$")
+	if len(parts) == 1 {
+		// Legacy unsalted SHA-256
+		ok = constantTimeEqual(legacyAPIKeyHash(fullKey), stored)
+		return ok, ok && h.Peppered()
+	}
+	if len(parts) != 3 || parts[0] != hmacHashScheme || h == nil {
+		return false, false
+	}
+
+	pepper, known := h.peppers[parts[1]]
+	if !known {
+		return false, false
+	}
+	ok = constantTimeEqual(h.digest(pepper, fullKey), parts[2])
+	return ok, ok && parts[1] != h.current
+}
+
+func (h *APIKeyHasher) digest(pepper []byte, fullKey string) string {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(fullKey))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func legacyAPIKeyHash(fullKey string) string {
+	hash := sha256.Sum256([]byte(fullKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// APIKeyDailyUsage holds request counts for one key on one UTC day
+type APIKeyDailyUsage struct {
+	APIKeyID     int       `json:"api_key_id"`
+	UsageDate    time.Time `json:"usage_date"`
+	RequestCount int64     `json:"request_count"`
+	ErrorCount   int64     `json:"error_count"`
+}
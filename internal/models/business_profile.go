@@ -0,0 +1,69 @@
+package models
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Card schemes print between 5 and 22 characters of a statement descriptor
+const (
+	MinStatementDescriptorLen = 5
+	MaxStatementDescriptorLen = 22
+)
+
+var (
+	// statementDescriptorChars are the Latin characters schemes accept; <, >, \, ', " and * are rejected
+	statementDescriptorChars = regexp.MustCompile(`^[A-Z0-9 .,\-&#/+_:()!?@]+$`)
+	statementDescriptorAlpha = regexp.MustCompile(`[A-Z]`)
+	brandColorPattern        = regexp.MustCompile(`^#(?:[0-9A-F]{3}|[0-9A-F]{6})$`)
+	phoneNumberPattern       = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	phoneSeparators          = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// NormalizeStatementDescriptor upper-cases and collapses whitespace in a card statement
+// descriptor and reports whether it meets scheme rules: 5 to 22 Latin characters with at
+// least one letter.
+func NormalizeStatementDescriptor(value string) (string, bool) {
+	descriptor := strings.ToUpper(strings.Join(strings.Fields(value), " "))
+	if len(descriptor) < MinStatementDescriptorLen || len(descriptor) > MaxStatementDescriptorLen {
+		return descriptor, false
+	}
+	return descriptor, statementDescriptorChars.MatchString(descriptor) && statementDescriptorAlpha.MatchString(descriptor)
+}
+
+// NormalizeBrandColor upper-cases a hex colour and expands the #RGB shorthand to #RRGGBB
+func NormalizeBrandColor(value string) (string, bool) {
+	color := strings.ToUpper(strings.TrimSpace(value))
+	if !brandColorPattern.MatchString(color) {
+		return color, false
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, true
+}
+
+// NormalizePhoneNumber strips separators from a phone number and reports whether the
+// result is in E.164 format
+func NormalizePhoneNumber(value string) (string, bool) {
+	phone := phoneSeparators.Replace(strings.TrimSpace(value))
+	return phone, phoneNumberPattern.MatchString(phone)
+}
+
+// NormalizeWebURL reports whether value is an absolute http(s) URL. When httpsOnly is
+// set, plain http is rejected, as for assets loaded into the checkout page.
+func NormalizeWebURL(value string, httpsOnly bool) (string, bool) {
+	raw := strings.TrimSpace(value)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return raw, false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "https" && (httpsOnly || scheme != "http") {
+		return raw, false
+	}
+	u.Scheme = scheme
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), true
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PlatformCurrency is the currency merchants get when their country has no local currency we settle in
+const PlatformCurrency = "NGN"
+
+// countryCurrencies maps countries to the local currency merchants there are onboarded with
+var countryCurrencies = map[string]string{
+	"NG": "NGN",
+	"GH": "GHS",
+	"KE": "KES",
+	"UG": "UGX",
+	"TZ": "TZS",
+	"RW": "RWF",
+	"ZA": "ZAR",
+	"EG": "EGP",
+	"US": "USD",
+	"GB": "GBP",
+}
+
+// supportedCurrencies are the ISO 4217 codes merchants can hold balances in
+var supportedCurrencies = map[string]bool{
+	"NGN": true,
+	"GHS": true,
+	"KES": true,
+	"UGX": true,
+	"TZS": true,
+	"RWF": true,
+	"ZAR": true,
+	"EGP": true,
+	"USD": true,
+	"GBP": true,
+	"EUR": true,
+}
+
+// DefaultCurrencyForCountry returns the currency a merchant registered in country starts with
+func DefaultCurrencyForCountry(country string) string {
+	if currency, ok := countryCurrencies[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return currency
+	}
+	return PlatformCurrency
+}
+
+// NormalizeCurrencyCode upper-cases a currency code and reports whether merchants can hold balances in it
+func NormalizeCurrencyCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return code, supportedCurrencies[code]
+}
+
+// MerchantCurrency is a currency a merchant may transact and settle in. Each one has its
+// own wallet, settlement config and balance.
+type MerchantCurrency struct {
+	MerchantID int       `json:"merchant_id"`
+	Currency   string    `json:"currency"`
+	IsDefault  bool      `json:"is_default"` // Derived from the merchant's country at onboarding
+	EnabledBy  string    `json:"enabled_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers the response to a request sent with an Idempotency-Key
+// so a retried request gets the same response instead of repeating the side effects.
+// ResponseStatus is zero while the original request is still being processed.
+type IdempotencyRecord struct {
+	Scope          string
+	Key            string
+	RequestHash    string
+	ResponseStatus int
+	ResponseBody   []byte
+	CreatedAt      time.Time
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// KYC business types
+const (
+	KYCBusinessRegistered    = "registered"
+	KYCBusinessStartup       = "startup"
+	KYCBusinessSmallBusiness = "small_business"
+	KYCBusinessIndividual    = "individual" // Light-tier sub-merchants only
+)
+
+// KYCBusinessTypes lists the business types in the order forms offer them
+var KYCBusinessTypes = []string{KYCBusinessRegistered, KYCBusinessStartup, KYCBusinessSmallBusiness, KYCBusinessIndividual}
+
+// KYCField is a submission field a KYC profile asks for. Name is the field's key in the
+// submission request.
+type KYCField struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Required bool   `json:"required"`
+	Pattern  string `json:"pattern,omitempty"` // Regular expression the trimmed value must match; identifiers and postal codes are upper-cased first
+	Hint     string `json:"hint,omitempty"`    // Human-readable description of Pattern
+
+	pattern *regexp.Regexp
+}
+
+// Matches reports whether value satisfies the field's format
+func (f KYCField) Matches(value string) bool {
+	return f.pattern == nil || f.pattern.MatchString(value)
+}
+
+// KYCDocument is a document a KYC profile asks for, keyed by Type in the submission's documents
+type KYCDocument struct {
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Required bool   `json:"required"`
+}
+
+// KYCRequirements is what a merchant of a business type in a country must submit for KYC
+type KYCRequirements struct {
+	Country       string        `json:"country"`
+	BusinessType  string        `json:"business_type"`
+	LightTierOnly bool          `json:"light_tier_only"`
+	Fields        []KYCField    `json:"fields"`
+	Documents     []KYCDocument `json:"documents"`
+}
+
+func kycRequired(name, label string) KYCField {
+	return KYCField{Name: name, Label: label, Required: true}
+}
+
+func kycOptional(name, label string) KYCField {
+	return KYCField{Name: name, Label: label}
+}
+
+// kycFormatted adds a format check to a field
+func kycFormatted(f KYCField, pattern, hint string) KYCField {
+	f.Pattern = pattern
+	f.Hint = hint
+	f.pattern = regexp.MustCompile(pattern)
+	return f
+}
+
+func kycDocument(docType, label string) KYCDocument {
+	return KYCDocument{Type: docType, Label: label, Required: true}
+}
+
+// kycCountryProfile holds the country-specific parts of every business type's requirements
+type kycCountryProfile struct {
+	registrationNumber KYCField
+	taxID              KYCField
+	directorID         KYCField
+	region             KYCField
+	postalCode         KYCField
+	registrationDoc    KYCDocument
+	businessNameDoc    KYCDocument // Registration certificate for sole proprietors
+}
+
+var defaultKYCProfile = kycCountryProfile{
+	registrationNumber: kycFormatted(kycRequired("registration_number", "Business registration number"), `^[A-Z0-9/\-]{4,30}$`, "4 to 30 letters, digits, / or -"),
+	taxID:              kycFormatted(kycOptional("tax_id", "Tax identification number"), `^[A-Z0-9/\-]{4,30}$`, "4 to 30 letters, digits, / or -"),
+	directorID:         kycFormatted(kycRequired("director_id_number", "Director's national ID or passport number"), `^[A-Z0-9\-]{5,30}$`, "5 to 30 letters, digits or -"),
+	region:             kycOptional("state", "State, province or region"),
+	postalCode:         kycOptional("postal_code", "Postal code"),
+	registrationDoc:    kycDocument("certificate_of_incorporation", "Certificate of incorporation"),
+	businessNameDoc:    kycDocument("business_registration_certificate", "Business registration certificate"),
+}
+
+// kycCountryProfiles are the countries with local identifier formats. Other countries use
+// defaultKYCProfile.
+var kycCountryProfiles = map[string]kycCountryProfile{
+	"NG": {
+		registrationNumber: kycFormatted(kycRequired("registration_number", "CAC registration number"), `^(RC|BN|IT)?[0-9]{5,8}$`, "RC, BN or IT followed by 5 to 8 digits"),
+		taxID:              kycFormatted(kycOptional("tax_id", "Tax identification number (TIN)"), `^[0-9]{8}-?[0-9]{4}$`, "12 digits, e.g. 12345678-0001"),
+		directorID:         kycFormatted(kycRequired("director_id_number", "Director's BVN"), `^[0-9]{11}$`, "11 digits"),
+		region:             kycRequired("state", "State"),
+		postalCode:         kycFormatted(kycOptional("postal_code", "Postal code"), `^[0-9]{6}$`, "6 digits"),
+		registrationDoc:    kycDocument("certificate_of_incorporation", "CAC certificate of incorporation"),
+		businessNameDoc:    kycDocument("business_name_certificate", "CAC business name certificate"),
+	},
+	"GH": {
+		registrationNumber: kycFormatted(kycRequired("registration_number", "Registrar-General's registration number"), `^(CS|BN|CG|PL)[0-9]{6,9}$`, "CS, BN, CG or PL followed by 6 to 9 digits"),
+		taxID:              kycFormatted(kycOptional("tax_id", "GRA taxpayer identification number"), `^[CGPQV][0-9]{10}$`, "C, G, P, Q or V followed by 10 digits"),
+		directorID:         kycFormatted(kycRequired("director_id_number", "Director's Ghana Card number"), `^GHA-[0-9]{9}-[0-9]$`, "GHA-123456789-0"),
+		region:             kycRequired("state", "Region"),
+		postalCode:         kycFormatted(kycRequired("postal_code", "GhanaPostGPS digital address"), `^[A-Z]{2}-[0-9]{3,4}-[0-9]{3,4}$`, "e.g. GA-123-4567"),
+		registrationDoc:    kycDocument("certificate_of_incorporation", "Certificate of incorporation"),
+		businessNameDoc:    kycDocument("business_registration_certificate", "Certificate of registration (sole proprietorship)"),
+	},
+	"KE": {
+		registrationNumber: kycFormatted(kycRequired("registration_number", "Business Registration Service number"), `^(PVT|CPR|BN|LLP)-?[A-Z0-9]{6,10}$`, "PVT, CPR, BN or LLP followed by 6 to 10 letters or digits"),
+		taxID:              kycFormatted(kycRequired("tax_id", "KRA PIN"), `^[AP][0-9]{9}[A-Z]$`, "A or P, 9 digits and a letter"),
+		directorID:         kycFormatted(kycRequired("director_id_number", "Director's national ID number"), `^[0-9]{7,8}$`, "7 or 8 digits"),
+		region:             kycRequired("state", "County"),
+		postalCode:         kycFormatted(kycOptional("postal_code", "Postal code"), `^[0-9]{5}$`, "5 digits"),
+		registrationDoc:    kycDocument("certificate_of_incorporation", "Certificate of incorporation and CR12"),
+		businessNameDoc:    kycDocument("business_name_certificate", "Business name registration certificate"),
+	},
+	"ZA": {
+		registrationNumber: kycFormatted(kycRequired("registration_number", "CIPC registration number"), `^[0-9]{4}/[0-9]{6}/[0-9]{2}$`, "YYYY/NNNNNN/NN"),
+		taxID:              kycFormatted(kycOptional("tax_id", "SARS income tax reference number"), `^[0-9]{10}$`, "10 digits"),
+		directorID:         kycFormatted(kycRequired("director_id_number", "Director's South African ID number"), `^[0-9]{13}$`, "13 digits"),
+		region:             kycRequired("state", "Province"),
+		postalCode:         kycFormatted(kycRequired("postal_code", "Postal code"), `^[0-9]{4}$`, "4 digits"),
+		registrationDoc:    kycDocument("certificate_of_incorporation", "CIPC registration certificate (CoR 14.3)"),
+		businessNameDoc:    kycDocument("business_registration_certificate", "Proof of business registration"),
+	},
+}
+
+// KYCRequirementsFor returns what merchants of businessType in country must submit. It
+// reports false for unknown business types.
+func KYCRequirementsFor(country, businessType string) (*KYCRequirements, bool) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	p, ok := kycCountryProfiles[country]
+	if !ok {
+		p = defaultKYCProfile
+	}
+
+	business := []KYCField{
+		kycRequired("business_name", "Registered business name"),
+		kycRequired("business_category", "Business category"),
+		kycRequired("business_address", "Business address"),
+		kycRequired("city", "City"),
+		p.region,
+		p.postalCode,
+	}
+	director := []KYCField{
+		kycRequired("director_name", "Director's full name"),
+		p.directorID,
+		kycFormatted(kycRequired("director_phone", "Director's phone number"), `^\+[1-9][0-9]{7,14}$`, "E.164 format, e.g. +233201234567"),
+		kycRequired("director_email", "Director's email address"),
+	}
+	directorIDDoc := kycDocument("director_id", "Director's government-issued ID")
+	proofOfAddress := kycDocument("proof_of_address", "Proof of business address")
+
+	r := &KYCRequirements{Country: country, BusinessType: businessType}
+	switch businessType {
+	case KYCBusinessRegistered:
+		r.Fields = append(append(business,
+			p.registrationNumber,
+			p.taxID,
+			kycFormatted(kycRequired("incorporation_date", "Date of incorporation"), `^[0-9]{4}-[0-9]{2}-[0-9]{2}$`, "YYYY-MM-DD"),
+		), director...)
+		r.Documents = []KYCDocument{p.registrationDoc, directorIDDoc, proofOfAddress}
+	case KYCBusinessSmallBusiness:
+		r.Fields = append(append(business, p.registrationNumber, kycOptionalField(p.taxID)), director...)
+		r.Documents = []KYCDocument{p.businessNameDoc, directorIDDoc, proofOfAddress}
+	case KYCBusinessStartup:
+		r.Fields = append(append(business, kycOptionalField(p.registrationNumber), kycOptionalField(p.taxID)), director...)
+		r.Documents = []KYCDocument{directorIDDoc, proofOfAddress}
+	case KYCBusinessIndividual:
+		// Individual sellers are vouched for by their marketplace, so only the person is checked
+		r.LightTierOnly = true
+		r.Fields = []KYCField{
+			kycRequired("director_name", "Full name"),
+			kycPersonal(p.directorID),
+			kycPersonal(director[2]),
+			kycPersonal(director[3]),
+			kycRequired("business_address", "Home or trading address"),
+			kycRequired("city", "City"),
+			p.region,
+		}
+		r.Documents = []KYCDocument{kycDocument("director_id", "Government-issued ID")}
+	default:
+		return nil, false
+	}
+	return r, true
+}
+
+func kycOptionalField(f KYCField) KYCField {
+	f.Required = false
+	return f
+}
+
+// kycPersonal relabels a director field for an individual seller, e.g. "Director's BVN" becomes "BVN"
+func kycPersonal(f KYCField) KYCField {
+	label := strings.TrimPrefix(f.Label, "Director's ")
+	f.Label = strings.ToUpper(label[:1]) + label[1:]
+	return f
+}
@@ -6,12 +6,30 @@ import "time"
 type KYCStatus string
 
 const (
-	KYCStatusPending  KYCStatus = "pending"
-	KYCStatusApproved KYCStatus = "approved"
-	KYCStatusRejected KYCStatus = "rejected"
+	KYCStatusPending    KYCStatus = "pending"
+	KYCStatusApproved   KYCStatus = "approved"
+	KYCStatusRejected   KYCStatus = "rejected"
 	KYCStatusNotStarted KYCStatus = "not_started"
 )
 
+// IsValidKYCStatus reports whether status is a known KYC status
+func IsValidKYCStatus(status KYCStatus) bool {
+	switch status {
+	case KYCStatusPending, KYCStatusApproved, KYCStatusRejected, KYCStatusNotStarted:
+		return true
+	}
+	return false
+}
+
+// KYCTier sets how much verification a merchant must complete
+type KYCTier string
+
+const (
+	KYCTierStandard KYCTier = "standard"
+	// KYCTierLight is available to sub-merchants whose approved parent vouches for them
+	KYCTierLight KYCTier = "light"
+)
+
 // MerchantStatus represents the overall status of a merchant account
 type MerchantStatus string
 
@@ -19,8 +37,34 @@ const (
 	MerchantStatusActive    MerchantStatus = "active"
 	MerchantStatusSuspended MerchantStatus = "suspended"
 	MerchantStatusInactive  MerchantStatus = "inactive"
+	// MerchantStatusClosed is terminal; it is only reached through the closure workflow
+	MerchantStatusClosed MerchantStatus = "closed"
 )
 
+// merchantStatusTransitions lists the statuses each status may move to
+var merchantStatusTransitions = map[MerchantStatus][]MerchantStatus{
+	MerchantStatusInactive:  {MerchantStatusActive, MerchantStatusSuspended, MerchantStatusClosed},
+	MerchantStatusActive:    {MerchantStatusSuspended, MerchantStatusInactive, MerchantStatusClosed},
+	MerchantStatusSuspended: {MerchantStatusActive, MerchantStatusInactive, MerchantStatusClosed},
+	MerchantStatusClosed:    {},
+}
+
+// IsValidMerchantStatus reports whether status is a known merchant status
+func IsValidMerchantStatus(status MerchantStatus) bool {
+	_, ok := merchantStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionTo reports whether a merchant in status may move to next
+func (status MerchantStatus) CanTransitionTo(next MerchantStatus) bool {
+	for _, allowed := range merchantStatusTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Merchant represents a merchant entity in the system
 type Merchant struct {
 	ID           int            `json:"id"`
@@ -30,8 +74,23 @@ type Merchant struct {
 	Country      string         `json:"country"`
 	Status       MerchantStatus `json:"status"`
 	KYCStatus    KYCStatus      `json:"kyc_status"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
+	KYCTier      KYCTier        `json:"kyc_tier"`
+	// ParentID links a marketplace sub-merchant to the platform merchant that onboarded it
+	ParentID *int `json:"parent_id,omitempty"`
+	// Business profile shown on checkout pages and customer receipts
+	SupportEmail        string `json:"support_email,omitempty"`
+	SupportPhone        string `json:"support_phone,omitempty"`
+	Website             string `json:"website,omitempty"`
+	LogoURL             string `json:"logo_url,omitempty"`
+	BrandColor          string `json:"brand_color,omitempty"`
+	StatementDescriptor string `json:"statement_descriptor,omitempty"`
+	// KYCReviewRequired is set when a KYC-relevant field changes after submission
+	KYCReviewRequired bool       `json:"kyc_review_required"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+	// AnonymizedAt is set once personal data has been removed after the retention period
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 // CanTransact checks if a merchant is allowed to process transactions
@@ -40,6 +99,11 @@ func (m *Merchant) CanTransact() bool {
 	return m.Status == MerchantStatusActive && m.KYCStatus == KYCStatusApproved
 }
 
+// IsSubMerchant reports whether the merchant was onboarded under a parent merchant
+func (m *Merchant) IsSubMerchant() bool {
+	return m.ParentID != nil
+}
+
 // IsKYCCompleted checks if KYC process is completed (approved or rejected)
 func (m *Merchant) IsKYCCompleted() bool {
 	return m.KYCStatus == KYCStatusApproved || m.KYCStatus == KYCStatusRejected
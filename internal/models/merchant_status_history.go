@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// StatusReasonCode explains why a merchant's status changed
+type StatusReasonCode string
+
+const (
+	ReasonKYCApproved      StatusReasonCode = "kyc_approved"
+	ReasonReviewCleared    StatusReasonCode = "review_cleared"
+	ReasonFraudSuspected   StatusReasonCode = "fraud_suspected"
+	ReasonComplianceReview StatusReasonCode = "compliance_review"
+	ReasonChargebackRisk   StatusReasonCode = "chargeback_risk"
+	ReasonUnpaidFees       StatusReasonCode = "unpaid_fees"
+	ReasonMerchantRequest  StatusReasonCode = "merchant_request"
+	ReasonDormant          StatusReasonCode = "dormant"
+	// ReasonOther must be accompanied by a note
+	ReasonOther StatusReasonCode = "other"
+)
+
+// statusReasonCodes lists the reason codes accepted when moving into each status
+var statusReasonCodes = map[MerchantStatus][]StatusReasonCode{
+	MerchantStatusActive:    {ReasonKYCApproved, ReasonReviewCleared, ReasonOther},
+	MerchantStatusSuspended: {ReasonFraudSuspected, ReasonComplianceReview, ReasonChargebackRisk, ReasonUnpaidFees, ReasonOther},
+	MerchantStatusInactive:  {ReasonMerchantRequest, ReasonDormant, ReasonOther},
+	MerchantStatusClosed:    {ReasonMerchantRequest, ReasonFraudSuspected, ReasonComplianceReview, ReasonDormant, ReasonOther},
+}
+
+// StatusReasonCodesFor returns the reason codes accepted when moving into status
+func StatusReasonCodesFor(status MerchantStatus) []StatusReasonCode {
+	return statusReasonCodes[status]
+}
+
+// MerchantStatusHistory records a single status change
+type MerchantStatusHistory struct {
+	ID         int              `json:"id"`
+	MerchantID int              `json:"merchant_id"`
+	FromStatus MerchantStatus   `json:"from_status"`
+	ToStatus   MerchantStatus   `json:"to_status"`
+	ReasonCode StatusReasonCode `json:"reason_code"`
+	Note       *string          `json:"note,omitempty"`
+	Actor      string           `json:"actor"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
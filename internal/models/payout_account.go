@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PayoutAccountStatus tracks whether a payout account may still be used
+type PayoutAccountStatus string
+
+const (
+	PayoutAccountStatusActive  PayoutAccountStatus = "active"
+	PayoutAccountStatusRemoved PayoutAccountStatus = "removed"
+)
+
+// PayoutAccount is a bank account settlements and payouts are sent to. New and changed
+// accounts only receive money once their cooling-off period has passed, which limits
+// the damage a compromised dashboard session can do.
+type PayoutAccount struct {
+	ID            int                 `json:"id"`
+	MerchantID    int                 `json:"merchant_id"`
+	BankCode      string              `json:"bank_code"`
+	AccountNumber string              `json:"-"` // Only shown masked to merchants
+	AccountName   string              `json:"account_name"`
+	Currency      string              `json:"currency"`
+	IsPrimary     bool                `json:"is_primary"`
+	Status        PayoutAccountStatus `json:"status"`
+	VerifiedAt    time.Time           `json:"verified_at"`
+	UsableFrom    time.Time           `json:"usable_from"` // End of the cooling-off period
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// IsUsable reports whether money may be sent to the account at now
+func (a *PayoutAccount) IsUsable(now time.Time) bool {
+	return a.Status == PayoutAccountStatusActive && !now.Before(a.UsableFrom)
+}
+
+// MaskedAccountNumber hides all but the last four digits of the account number
+func (a *PayoutAccount) MaskedAccountNumber() string {
+	if len(a.AccountNumber) <= 4 {
+		return a.AccountNumber
+	}
+	return strings.Repeat("*", len(a.AccountNumber)-4) + a.AccountNumber[len(a.AccountNumber)-4:]
+}
+
+// AccountNamesMatch reports whether the name a merchant entered matches the name the bank
+// returned, ignoring case, punctuation and word order
+func AccountNamesMatch(entered, resolved string) bool {
+	normalize := func(name string) map[string]bool {
+		words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+			return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		})
+		set := make(map[string]bool, len(words))
+		for _, w := range words {
+			set[w] = true
+		}
+		return set
+	}
+	a, b := normalize(entered), normalize(resolved)
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for w := range a {
+		if !b[w] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// TeamRole names what a person on a merchant's team may do
+type TeamRole string
+
+const (
+	// TeamRoleOwner may do everything, including managing other owners and closing the account
+	TeamRoleOwner     TeamRole = "owner"
+	TeamRoleAdmin     TeamRole = "admin"
+	TeamRoleDeveloper TeamRole = "developer"
+	TeamRoleFinance   TeamRole = "finance"
+)
+
+// AllTeamRoles lists every role a team member may hold
+var AllTeamRoles = []TeamRole{
+	TeamRoleOwner,
+	TeamRoleAdmin,
+	TeamRoleDeveloper,
+	TeamRoleFinance,
+}
+
+// IsValidTeamRole reports whether role is a known team role
+func IsValidTeamRole(role TeamRole) bool {
+	for _, r := range AllTeamRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamMemberStatus tracks a member from invitation to removal
+type TeamMemberStatus string
+
+const (
+	TeamMemberStatusInvited TeamMemberStatus = "invited"
+	TeamMemberStatusActive  TeamMemberStatus = "active"
+	TeamMemberStatusRevoked TeamMemberStatus = "revoked"
+)
+
+// invitationTokenPrefix marks team invitation tokens
+const invitationTokenPrefix = "inv_"
+
+// TeamMember is a person who may act on a merchant. Invitations are members in the
+// invited status; UserID is the auth-service user bound when the invitation is accepted.
+type TeamMember struct {
+	ID                    int              `json:"id"`
+	MerchantID            int              `json:"merchant_id"`
+	Email                 string           `json:"email"`
+	UserID                *string          `json:"user_id,omitempty"`
+	Role                  TeamRole         `json:"role"`
+	Status                TeamMemberStatus `json:"status"`
+	InvitationTokenHash   string           `json:"-"` // Never expose the hash
+	InvitationTokenPrefix string           `json:"-"`
+	InvitationExpiresAt   *time.Time       `json:"invitation_expires_at,omitempty"`
+	InvitedBy             string           `json:"invited_by,omitempty"`
+	AcceptedAt            *time.Time       `json:"accepted_at,omitempty"`
+	RevokedAt             *time.Time       `json:"revoked_at,omitempty"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+// HasRole reports whether the member holds any of roles. Owners hold every role.
+func (m *TeamMember) HasRole(roles ...TeamRole) bool {
+	if m.Role == TeamRoleOwner {
+		return true
+	}
+	for _, role := range roles {
+		if m.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateInvitationToken creates a new inv_ token for member, hashed for storage with hasher
+func GenerateInvitationToken(member *TeamMember, hasher *APIKeyHasher) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := invitationTokenPrefix + base64.URLEncoding.EncodeToString(tokenBytes)
+
+	member.InvitationTokenHash = hasher.Hash(token)
+	member.InvitationTokenPrefix = token[:APIKeyPrefixLength]
+	return token, nil
+}
+
+// LooksLikeInvitationToken reports whether the value carries the inv_ token prefix
+func LooksLikeInvitationToken(value string) bool {
+	return strings.HasPrefix(value, invitationTokenPrefix)
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// MerchantTwoFactor is a merchant's TOTP enrollment. The secret is stored encrypted
+// and recovery codes are stored hashed; neither is ever serialised.
+type MerchantTwoFactor struct {
+	MerchantID         int        `json:"merchant_id"`
+	EncryptedSecret    string     `json:"-"`
+	Enabled            bool       `json:"enabled"`
+	RecoveryCodeHashes []string   `json:"-"`
+	LastUsedStep       int64      `json:"-"` // TOTP time step of the last accepted code
+	FailedAttempts     int        `json:"-"` // Code checks since the last correct code
+	LockedUntil        *time.Time `json:"-"`
+	EnabledAt          *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// ConfirmationAction names the sensitive action a two-factor confirmation token unlocks.
+// A token unlocks one request for its action and no other.
+type ConfirmationAction string
+
+const (
+	ConfirmAPIKeyIssue       ConfirmationAction = "api_keys:issue" // Test and live key pairs
+	ConfirmAPIKeyRotate      ConfirmationAction = "api_keys:rotate"
+	ConfirmAPIKeyRestricted  ConfirmationAction = "api_keys:restricted"
+	ConfirmAPIKeyIPAllowlist ConfirmationAction = "api_keys:ip_allowlist"
+	ConfirmAPIKeyRevoke      ConfirmationAction = "api_keys:revoke" // Also ends a rotated key's grace period
+	ConfirmSettlementConfig  ConfirmationAction = "settlement_config:write"
+	ConfirmPayoutAccounts    ConfirmationAction = "payout_accounts:write"
+	ConfirmMerchantClose     ConfirmationAction = "merchant:close"
+	ConfirmTwoFactorDisable  ConfirmationAction = "two_factor:disable"
+)
+
+// IsValidConfirmationAction reports whether action is one a token can be issued for
+func IsValidConfirmationAction(action ConfirmationAction) bool {
+	switch action {
+	case ConfirmAPIKeyIssue, ConfirmAPIKeyRotate, ConfirmAPIKeyRestricted, ConfirmAPIKeyIPAllowlist,
+		ConfirmAPIKeyRevoke, ConfirmSettlementConfig, ConfirmPayoutAccounts, ConfirmMerchantClose,
+		ConfirmTwoFactorDisable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps claimed keys in process memory. Replays are only caught by the
+// instance that saw the original request, so it suits local development and
+// single-replica deployments.
+type MemoryStore struct {
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		expiries:  make(map[string]time.Time),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > memorySweepInterval {
+		s.sweep(now)
+	}
+	if expiry, ok := s.expiries[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.expiries[key] = now.Add(ttl)
+	return true, nil
+}
+
+// sweep drops expired keys
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, expiry := range s.expiries {
+		if !now.Before(expiry) {
+			delete(s.expiries, key)
+		}
+	}
+	s.lastSweep = now
+}
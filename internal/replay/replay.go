@@ -0,0 +1,13 @@
+// Package replay remembers values that may only be used once, such as request signatures
+package replay
+
+import (
+	"context"
+	"time"
+)
+
+// Store records keys for a limited time. Implementations must be safe for concurrent use.
+type Store interface {
+	// Claim records key for ttl and reports whether this was its first use within that time
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrAdminNotFound = errors.New("admin not found")
+	ErrAdminExists   = errors.New("an admin with this email already exists")
+)
+
+const adminColumns = `id, email, name, roles, token_hash, token_prefix, is_active, created_at`
+
+func scanAdmin(row rowScanner) (*models.Admin, error) {
+	admin := &models.Admin{}
+	err := row.Scan(
+		&admin.ID,
+		&admin.Email,
+		&admin.Name,
+		(*pq.StringArray)(&admin.Roles),
+		&admin.TokenHash,
+		&admin.TokenPrefix,
+		&admin.IsActive,
+		&admin.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+type AdminRepository struct {
+	db *sql.DB
+}
+
+func NewAdminRepository(db *sql.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+func (r *AdminRepository) Create(ctx context.Context, admin *models.Admin) error {
+	return r.create(ctx, r.db, admin)
+}
+
+// CreateFirstSuperAdmin stores admin unless an active super admin already exists. It
+// reports false when one does. Concurrent callers are serialized so only one is created.
+func (r *AdminRepository) CreateFirstSuperAdmin(ctx context.Context, admin *models.Admin) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('admin_users.bootstrap'))`); err != nil {
+		return false, err
+	}
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM admin_users WHERE is_active AND $1 = ANY(roles))
+	`, string(models.AdminRoleSuperAdmin)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if err := r.create(ctx, tx, admin); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *AdminRepository) create(ctx context.Context, q queryRower, admin *models.Admin) error {
+	query := `
+		INSERT INTO admin_users (email, name, roles, token_hash, token_prefix, is_active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		admin.Email,
+		admin.Name,
+		pq.StringArray(admin.Roles),
+		admin.TokenHash,
+		admin.TokenPrefix,
+		admin.IsActive,
+		admin.CreatedAt,
+	).Scan(&admin.ID)
+	if isUniqueViolation(err) {
+		return ErrAdminExists
+	}
+	return err
+}
+
+// GetByTokenPrefix returns the admin whose token starts with prefix
+func (r *AdminRepository) GetByTokenPrefix(ctx context.Context, prefix string) (*models.Admin, error) {
+	query := `
+		SELECT ` + adminColumns + `
+		FROM admin_users
+		WHERE token_prefix = $1
+	`
+	admin, err := scanAdmin(r.db.QueryRowContext(ctx, query, prefix))
+	if err == sql.ErrNoRows {
+		return nil, ErrAdminNotFound
+	}
+	return admin, err
+}
+
+func (r *AdminRepository) List(ctx context.Context) ([]*models.Admin, error) {
+	query := `
+		SELECT ` + adminColumns + `
+		FROM admin_users
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var admins []*models.Admin
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, admin)
+	}
+	return admins, rows.Err()
+}
+
+// UpdateRoles replaces the roles held by an admin
+func (r *AdminRepository) UpdateRoles(ctx context.Context, id int, roles []string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE admin_users SET roles = $2 WHERE id = $1`, id, pq.StringArray(roles))
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAdminNotFound
+	}
+	return nil
+}
+
+// UpdateHash replaces an admin's stored token hash, provided it still holds oldHash
+func (r *AdminRepository) UpdateHash(ctx context.Context, id int, oldHash, newHash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE admin_users SET token_hash = $3 WHERE id = $1 AND token_hash = $2`, id, oldHash, newHash)
+	return err
+}
+
+// Deactivate disables an admin's token
+func (r *AdminRepository) Deactivate(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE admin_users SET is_active = false WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAdminNotFound
+	}
+	return nil
+}
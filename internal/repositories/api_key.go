@@ -3,10 +3,48 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/lib/pq"
 )
 
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const apiKeyColumns = `id, merchant_id, key_hash, key_prefix, key_type, environment, scopes, allowed_ips, is_active,
+		last_used_at, last_used_ip, expires_at, revoked_at, revoked_by, revoke_reason, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := row.Scan(
+		&key.ID,
+		&key.MerchantID,
+		&key.KeyHash,
+		&key.KeyPrefix,
+		&key.KeyType,
+		&key.Environment,
+		(*pq.StringArray)(&key.Scopes),
+		(*pq.StringArray)(&key.AllowedIPs),
+		&key.IsActive,
+		&key.LastUsedAt,
+		&key.LastUsedIP,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.RevokedBy,
+		&key.RevokeReason,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
 type APIKeyRepository struct {
 	db *sql.DB
 }
@@ -16,12 +54,22 @@ func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
 }
 
 func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.create(ctx, r.db, key)
+}
+
+// queryRower is satisfied by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (r *APIKeyRepository) create(ctx context.Context, q queryRower, key *models.APIKey) error {
 	query := `
-		INSERT INTO api_keys (merchant_id, key_hash, key_prefix, key_type, environment, is_active, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO api_keys (merchant_id, key_hash, key_prefix, key_type, environment, scopes, is_active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
-	return r.db.QueryRowContext(
+	return q.QueryRowContext(
 		ctx,
 		query,
 		key.MerchantID,
@@ -29,6 +77,7 @@ func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error
 		key.KeyPrefix,
 		key.KeyType,
 		key.Environment,
+		pq.StringArray(key.Scopes),
 		key.IsActive,
 		key.CreatedAt,
 	).Scan(&key.ID)
@@ -36,12 +85,29 @@ func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error
 
 func (r *APIKeyRepository) ListByMerchantID(ctx context.Context, merchantID int) ([]*models.APIKey, error) {
 	query := `
-		SELECT id, merchant_id, key_hash, key_prefix, key_type, environment, is_active, last_used_at, expires_at, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
 		WHERE merchant_id = $1 AND is_active = true
+		  AND (expires_at IS NULL OR expires_at > NOW())
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	return r.queryKeys(ctx, query, merchantID)
+}
+
+// ListPendingExpiry returns active keys that are still valid but scheduled to expire
+func (r *APIKeyRepository) ListPendingExpiry(ctx context.Context, merchantID int) ([]*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE merchant_id = $1 AND is_active = true
+		  AND expires_at IS NOT NULL AND expires_at > NOW()
+		ORDER BY expires_at ASC
+	`
+	return r.queryKeys(ctx, query, merchantID)
+}
+
+func (r *APIKeyRepository) queryKeys(ctx context.Context, query string, args ...interface{}) ([]*models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,19 +115,8 @@ func (r *APIKeyRepository) ListByMerchantID(ctx context.Context, merchantID int)
 
 	var keys []*models.APIKey
 	for rows.Next() {
-		key := &models.APIKey{}
-		if err := rows.Scan(
-			&key.ID,
-			&key.MerchantID,
-			&key.KeyHash,
-			&key.KeyPrefix,
-			&key.KeyType,
-			&key.Environment,
-			&key.IsActive,
-			&key.LastUsedAt,
-			&key.ExpiresAt,
-			&key.CreatedAt,
-		); err != nil {
+		key, err := scanAPIKey(rows)
+		if err != nil {
 			return nil, err
 		}
 		keys = append(keys, key)
@@ -70,37 +125,236 @@ func (r *APIKeyRepository) ListByMerchantID(ctx context.Context, merchantID int)
 	return keys, rows.Err()
 }
 
-func (r *APIKeyRepository) DeactivateByMerchantAndType(ctx context.Context, merchantID int, keyType models.APIKeyType, env models.Environment) error {
+// CreateReplacement stores key and, in the same transaction, sets an expiry on the
+// merchant's other active keys of its type and environment. An earlier existing expiry
+// is kept. It returns the number of keys scheduled to expire.
+func (r *APIKeyRepository) CreateReplacement(ctx context.Context, key *models.APIKey, expiresAt time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := r.create(ctx, tx, key); err != nil {
+		return 0, err
+	}
 	query := `
 		UPDATE api_keys
-		SET is_active = false
+		SET expires_at = LEAST(COALESCE(expires_at, $4), $4)
 		WHERE merchant_id = $1 AND key_type = $2 AND environment = $3
+		  AND is_active = true AND id <> $5
 	`
-	_, err := r.db.ExecContext(ctx, query, merchantID, keyType, env)
-	return err
+	res, err := tx.ExecContext(ctx, query, key.MerchantID, key.KeyType, key.Environment, expiresAt, key.ID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
 }
 
-func (r *APIKeyRepository) GetByPrefix(ctx context.Context, keyPrefix string) (*models.APIKey, error) {
+// ExpireNow immediately expires a key that is pending expiry
+func (r *APIKeyRepository) ExpireNow(ctx context.Context, merchantID, keyID int) error {
 	query := `
-		SELECT id, merchant_id, key_hash, key_prefix, key_type, environment, is_active, last_used_at, expires_at, created_at
+		UPDATE api_keys
+		SET expires_at = NOW()
+		WHERE id = $1 AND merchant_id = $2 AND is_active = true
+		  AND expires_at IS NOT NULL AND expires_at > NOW()
+	`
+	res, err := r.db.ExecContext(ctx, query, keyID, merchantID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Revoke deactivates a single active key and records who revoked it and why
+func (r *APIKeyRepository) Revoke(ctx context.Context, merchantID, keyID int, revokedBy, reason string) error {
+	query := `
+		UPDATE api_keys
+		SET is_active = false, revoked_at = NOW(), revoked_by = $3, revoke_reason = NULLIF($4, '')
+		WHERE id = $1 AND merchant_id = $2 AND is_active = true
+	`
+	res, err := r.db.ExecContext(ctx, query, keyID, merchantID, revokedBy, reason)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ListRevoked returns a merchant's revoked keys, most recently revoked first
+func (r *APIKeyRepository) ListRevoked(ctx context.Context, merchantID int) ([]*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
-		WHERE key_prefix = $1 AND is_active = true
+		WHERE merchant_id = $1 AND revoked_at IS NOT NULL
+		ORDER BY revoked_at DESC
 	`
-	key := &models.APIKey{}
-	err := r.db.QueryRowContext(ctx, query, keyPrefix).Scan(
-		&key.ID,
-		&key.MerchantID,
-		&key.KeyHash,
-		&key.KeyPrefix,
-		&key.KeyType,
-		&key.Environment,
-		&key.IsActive,
-		&key.LastUsedAt,
-		&key.ExpiresAt,
-		&key.CreatedAt,
-	)
+	return r.queryKeys(ctx, query, merchantID)
+}
+
+// DeactivateByMerchantAndEnvironment revokes every key a merchant holds in an environment
+func (r *APIKeyRepository) DeactivateByMerchantAndEnvironment(ctx context.Context, merchantID int, env models.Environment, revokedBy, reason string) (int64, error) {
+	query := `
+		UPDATE api_keys
+		SET is_active = false, revoked_at = NOW(), revoked_by = $3, revoke_reason = NULLIF($4, '')
+		WHERE merchant_id = $1 AND environment = $2 AND is_active = true
+	`
+	res, err := r.db.ExecContext(ctx, query, merchantID, env, revokedBy, reason)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ActiveKeyTypes returns the types of the active keys a merchant holds in an environment
+func (r *APIKeyRepository) ActiveKeyTypes(ctx context.Context, merchantID int, env models.Environment) (map[models.APIKeyType]bool, error) {
+	return r.activeKeyTypes(ctx, r.db, merchantID, env)
+}
+
+func (r *APIKeyRepository) activeKeyTypes(ctx context.Context, q queryRower, merchantID int, env models.Environment) (map[models.APIKeyType]bool, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT DISTINCT key_type
+		FROM api_keys
+		WHERE merchant_id = $1 AND environment = $2 AND is_active = true
+	`, merchantID, env)
 	if err != nil {
 		return nil, err
 	}
-	return key, nil
+	defer rows.Close()
+
+	types := make(map[models.APIKeyType]bool)
+	for rows.Next() {
+		var keyType models.APIKeyType
+		if err := rows.Scan(&keyType); err != nil {
+			return nil, err
+		}
+		types[keyType] = true
+	}
+	return types, rows.Err()
+}
+
+// CreateMissing stores the candidate keys whose type the merchant does not already hold
+// as an active key in env, and returns the ones it stored. The merchant row is locked so
+// concurrent callers cannot both issue a key, and the keys are stored all or nothing.
+func (r *APIKeyRepository) CreateMissing(ctx context.Context, merchantID int, env models.Environment, candidates []*models.APIKey) ([]*models.APIKey, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM merchants WHERE id = $1 FOR UPDATE`, merchantID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, ErrMerchantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := r.activeKeyTypes(ctx, tx, merchantID, env)
+	if err != nil {
+		return nil, err
+	}
+	var created []*models.APIKey
+	for _, key := range candidates {
+		if held[key.KeyType] {
+			continue
+		}
+		if err := r.create(ctx, tx, key); err != nil {
+			return nil, err
+		}
+		created = append(created, key)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetByPrefix returns the key stored under a prefix, including inactive keys so
+// callers can tell a revoked key apart from an unknown one.
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, keyPrefix string) (*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_prefix = $1
+		ORDER BY is_active DESC, created_at DESC
+		LIMIT 1
+	`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, keyPrefix))
+}
+
+// GetByID returns one of a merchant's keys regardless of its state
+func (r *APIKeyRepository) GetByID(ctx context.Context, merchantID, keyID int) (*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1 AND merchant_id = $2
+	`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyID, merchantID))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// UpdateAllowedIPs replaces the IP allowlist of one of a merchant's active keys
+func (r *APIKeyRepository) UpdateAllowedIPs(ctx context.Context, merchantID, keyID int, cidrs []string) error {
+	query := `
+		UPDATE api_keys
+		SET allowed_ips = $3
+		WHERE id = $1 AND merchant_id = $2 AND is_active = true
+	`
+	res, err := r.db.ExecContext(ctx, query, keyID, merchantID, pq.StringArray(cidrs))
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// UpdateHash replaces a key's stored hash, provided it still holds oldHash
+func (r *APIKeyRepository) UpdateHash(ctx context.Context, keyID int, oldHash, newHash string) error {
+	query := `
+		UPDATE api_keys
+		SET key_hash = $3
+		WHERE id = $1 AND key_hash = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, keyID, oldHash, newHash)
+	return err
+}
+
+// TouchLastUsed records the most recent use of a key; older timestamps never overwrite newer ones
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, keyID int, usedAt time.Time, ip string) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $2, last_used_ip = $3
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)
+	`
+	_, err := r.db.ExecContext(ctx, query, keyID, usedAt, ip)
+	return err
 }
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+type APIKeyUsageRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyUsageRepository(db *sql.DB) *APIKeyUsageRepository {
+	return &APIKeyUsageRepository{db: db}
+}
+
+// IncrementDaily adds request and error counts to a key's row for the given day
+func (r *APIKeyUsageRepository) IncrementDaily(ctx context.Context, keyID int, day time.Time, requests, errors int64) error {
+	query := `
+		INSERT INTO api_key_usage_daily (api_key_id, usage_date, request_count, error_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (api_key_id, usage_date)
+		DO UPDATE SET
+			request_count = api_key_usage_daily.request_count + $3,
+			error_count = api_key_usage_daily.error_count + $4
+	`
+	_, err := r.db.ExecContext(ctx, query, keyID, day.Format("2006-01-02"), requests, errors)
+	return err
+}
+
+// ListDaily returns a key's daily counts from since onwards, newest first
+func (r *APIKeyUsageRepository) ListDaily(ctx context.Context, keyID int, since time.Time) ([]models.APIKeyDailyUsage, error) {
+	query := `
+		SELECT api_key_id, usage_date, request_count, error_count
+		FROM api_key_usage_daily
+		WHERE api_key_id = $1 AND usage_date >= $2
+		ORDER BY usage_date DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, keyID, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []models.APIKeyDailyUsage
+	for rows.Next() {
+		var u models.APIKeyDailyUsage
+		if err := rows.Scan(&u.APIKeyID, &u.UsageDate, &u.RequestCount, &u.ErrorCount); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -3,11 +3,15 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/kodra-pay/merchant-service/internal/models"
 )
 
+// ErrMerchantClosed is returned for balance changes on a closed merchant
+var ErrMerchantClosed = errors.New("merchant is closed")
+
 type BalanceRepository struct {
 	db *sql.DB
 }
@@ -16,6 +20,32 @@ func NewBalanceRepository(db *sql.DB) *BalanceRepository {
 	return &BalanceRepository{db: db}
 }
 
+// withOpenMerchant runs fn in a transaction holding a share lock on the merchant row, so
+// the merchant cannot be closed until fn's balance change commits
+func (r *BalanceRepository) withOpenMerchant(ctx context.Context, merchantID int, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status models.MerchantStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM merchants WHERE id = $1 FOR SHARE`, merchantID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrMerchantNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status == models.MerchantStatusClosed {
+		return ErrMerchantClosed
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // GetOrCreate returns the merchant balance for a currency, creating it if it doesn't exist
 func (r *BalanceRepository) GetOrCreate(ctx context.Context, merchantID int, currency string) (*models.MerchantBalance, error) {
 	// Try to get existing balance
@@ -66,54 +96,93 @@ func (r *BalanceRepository) GetOrCreate(ctx context.Context, merchantID int, cur
 
 // AddToPending adds amount to pending balance (when transaction succeeds)
 func (r *BalanceRepository) AddToPending(ctx context.Context, merchantID int, currency string, amount int64) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO merchant_balances (merchant_id, currency, pending_balance, available_balance, total_volume)
-		VALUES ($1, $2, $3, 0, $3)
-		ON CONFLICT (merchant_id, currency)
-		DO UPDATE SET
-			pending_balance = merchant_balances.pending_balance + $3,
-			total_volume = merchant_balances.total_volume + $3,
-			updated_at = NOW()
-	`, merchantID, currency, amount)
-	return err
+	return r.withOpenMerchant(ctx, merchantID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO merchant_balances (merchant_id, currency, pending_balance, available_balance, total_volume)
+			VALUES ($1, $2, $3, 0, $3)
+			ON CONFLICT (merchant_id, currency)
+			DO UPDATE SET
+				pending_balance = merchant_balances.pending_balance + $3,
+				total_volume = merchant_balances.total_volume + $3,
+				updated_at = NOW()
+		`, merchantID, currency, amount)
+		return err
+	})
 }
 
 // SettlePending moves amount from pending to available (when settlement completes)
 func (r *BalanceRepository) SettlePending(ctx context.Context, merchantID int, currency string, amount int64) error {
-	res, err := r.db.ExecContext(ctx, `
-		UPDATE merchant_balances
-		SET pending_balance = pending_balance - $3,
-			available_balance = available_balance + $3,
-			updated_at = NOW()
-		WHERE merchant_id = $1 AND currency = $2
-		  AND pending_balance >= $3
-	`, merchantID, currency, amount)
-	if err != nil {
-		return err
-	}
+	return r.withOpenMerchant(ctx, merchantID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE merchant_balances
+			SET pending_balance = pending_balance - $3,
+				available_balance = available_balance + $3,
+				updated_at = NOW()
+			WHERE merchant_id = $1 AND currency = $2
+			  AND pending_balance >= $3
+		`, merchantID, currency, amount)
+		if err != nil {
+			return err
+		}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("insufficient pending balance to settle %d", amount)
-	}
-	return nil
+		rows, _ := res.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("insufficient pending balance to settle %d", amount)
+		}
+		return nil
+	})
 }
 
 // DeductFromAvailable deducts amount from available balance (when payout is made)
 func (r *BalanceRepository) DeductFromAvailable(ctx context.Context, merchantID int, currency string, amount int64) error {
-	res, err := r.db.ExecContext(ctx, `
-		UPDATE merchant_balances
-		SET available_balance = available_balance - $3,
-			updated_at = NOW()
-		WHERE merchant_id = $1 AND currency = $2 AND available_balance >= $3
-	`, merchantID, currency, amount)
+	return r.withOpenMerchant(ctx, merchantID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE merchant_balances
+			SET available_balance = available_balance - $3,
+				updated_at = NOW()
+			WHERE merchant_id = $1 AND currency = $2 AND available_balance >= $3
+		`, merchantID, currency, amount)
+		if err != nil {
+			return err
+		}
+
+		rows, _ := res.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("insufficient available balance")
+		}
+		return nil
+	})
+}
+
+// ListWithFunds returns a merchant's balances that still hold pending or available funds
+func (r *BalanceRepository) ListWithFunds(ctx context.Context, merchantID int) ([]models.MerchantBalance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, merchant_id, currency, pending_balance, available_balance, total_volume, created_at, updated_at
+		FROM merchant_balances
+		WHERE merchant_id = $1 AND (pending_balance <> 0 OR available_balance <> 0)
+		ORDER BY currency
+	`, merchantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("insufficient available balance")
+	var balances []models.MerchantBalance
+	for rows.Next() {
+		var balance models.MerchantBalance
+		if err := rows.Scan(
+			&balance.ID,
+			&balance.MerchantID,
+			&balance.Currency,
+			&balance.PendingBalance,
+			&balance.AvailableBalance,
+			&balance.TotalVolume,
+			&balance.CreatedAt,
+			&balance.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
 	}
-	return nil
+	return balances, rows.Err()
 }
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims key within scope for a new request. If the key was already used and
+// has not expired, the existing record is returned with reserved set to false.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (existing *models.IdempotencyRecord, reserved bool, err error) {
+	expiredBefore := time.Now().Add(-ttl)
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND key = $2 AND created_at < $3
+	`, scope, key, expiredBefore); err != nil {
+		return nil, false, err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (scope, key, request_hash, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (scope, key) DO NOTHING
+	`, scope, key, requestHash)
+	if err != nil {
+		return nil, false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if affected == 1 {
+		return nil, true, nil
+	}
+
+	record := &models.IdempotencyRecord{}
+	var status sql.NullInt64
+	err = r.db.QueryRowContext(ctx, `
+		SELECT scope, key, request_hash, response_status, response_body, created_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2
+	`, scope, key).Scan(&record.Scope, &record.Key, &record.RequestHash, &status, &record.ResponseBody, &record.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+	record.ResponseStatus = int(status.Int64)
+	return record, false, nil
+}
+
+// Complete stores the response sent for a reserved key
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, status int, body []byte) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET response_status = $3, response_body = $4
+		WHERE scope = $1 AND key = $2
+	`, scope, key, status, body)
+	return err
+}
+
+// Release frees a reserved key so the request can be retried
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
@@ -38,9 +38,10 @@ func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *models
 			merchant_id, business_type, business_name, cac_number, tin_number,
 			business_address, city, state, postal_code, incorporation_date,
 			business_category, director_name, director_bvn, director_phone, director_email,
-			documents, status, created_at, updated_at
+			documents, status, created_at, updated_at,
+			country, registration_number, tax_id, director_id_number
 		) VALUES (
-			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19
+			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23
 		)
 		RETURNING id
 	`
@@ -65,6 +66,10 @@ func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *models
 		submission.Status,
 		submission.CreatedAt,
 		submission.UpdatedAt,
+		submission.Country,
+		submission.RegistrationNumber,
+		submission.TaxID,
+		submission.DirectorIDNumber,
 	).Scan(&id)
 
 	if err == nil {
@@ -74,13 +79,24 @@ func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *models
 }
 
 func (r *KYCSubmissionRepository) GetLatestByMerchant(ctx context.Context, merchantID int) (*models.KYCSubmission, error) {
+	return r.getLatest(ctx, `merchant_id = $1`, merchantID)
+}
+
+// GetLatestApprovedByMerchant returns the merchant's most recent approved submission, or
+// nil if none was approved
+func (r *KYCSubmissionRepository) GetLatestApprovedByMerchant(ctx context.Context, merchantID int) (*models.KYCSubmission, error) {
+	return r.getLatest(ctx, `merchant_id = $1 AND status = 'approved'`, merchantID)
+}
+
+func (r *KYCSubmissionRepository) getLatest(ctx context.Context, where string, merchantID int) (*models.KYCSubmission, error) {
 	query := `
 		SELECT id, merchant_id, business_type, business_name, cac_number, tin_number,
 		       business_address, city, state, postal_code, incorporation_date,
 			   business_category, director_name, director_bvn, director_phone, director_email,
-			   documents, status, reviewer_id, review_notes, reviewed_at, created_at, updated_at
+			   documents, status, reviewer_id, review_notes, reviewed_at, created_at, updated_at,
+			   country, registration_number, tax_id, director_id_number
 		FROM kyc_submissions
-		WHERE merchant_id = $1
+		WHERE ` + where + `
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
@@ -93,6 +109,7 @@ func (r *KYCSubmissionRepository) GetLatestByMerchant(ctx context.Context, merch
 		&s.BusinessAddress, &s.City, &s.State, &s.PostalCode, &s.IncorporationDate,
 		&s.BusinessCategory, &s.DirectorName, &s.DirectorBVN, &s.DirectorPhone, &s.DirectorEmail,
 		&documents, &s.Status, &reviewerID, &s.ReviewNotes, &s.ReviewedAt, &s.CreatedAt, &s.UpdatedAt,
+		&s.Country, &s.RegistrationNumber, &s.TaxID, &s.DirectorIDNumber,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -122,7 +139,7 @@ func (r *KYCSubmissionRepository) ListByStatus(ctx context.Context, status strin
 		limit = 100
 	}
 	query := `
-		SELECT id, merchant_id, business_type, business_name, status, created_at, updated_at
+		SELECT id, merchant_id, country, business_type, business_name, status, created_at, updated_at
 		FROM kyc_submissions
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -137,7 +154,7 @@ func (r *KYCSubmissionRepository) ListByStatus(ctx context.Context, status strin
 	var list []*models.KYCSubmission
 	for rows.Next() {
 		var s models.KYCSubmission
-		if err := rows.Scan(&s.ID, &s.MerchantID, &s.BusinessType, &s.BusinessName, &s.Status, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.MerchantID, &s.Country, &s.BusinessType, &s.BusinessName, &s.Status, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, &s)
@@ -165,3 +182,17 @@ func (r *KYCSubmissionRepository) UpdateStatus(ctx context.Context, id int, stat
 	}
 	return nil
 }
+
+// AnonymizeByMerchant clears the personal data held on a merchant's KYC submissions,
+// keeping the review outcome
+func (r *KYCSubmissionRepository) AnonymizeByMerchant(ctx context.Context, merchantID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE kyc_submissions
+		SET business_address = '', city = '', state = '', postal_code = '',
+		    director_name = '', director_bvn = '', director_phone = '', director_email = '',
+		    cac_number = '', tin_number = '', registration_number = '', tax_id = '', director_id_number = '',
+		    documents = NULL, updated_at = NOW()
+		WHERE merchant_id = $1
+	`, merchantID)
+	return err
+}
@@ -3,15 +3,71 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log" // Added
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/kodra-pay/merchant-service/internal/models"
 )
 
+var (
+	ErrMerchantNotFound   = errors.New("merchant not found")
+	ErrMerchantEmailTaken = errors.New("email is already registered to another merchant")
+	// ErrStatusChanged is returned when a merchant's status changed concurrently
+	ErrStatusChanged = errors.New("merchant status changed concurrently")
+	// ErrMerchantHasFunds is returned when closing a merchant that still holds funds
+	ErrMerchantHasFunds = errors.New("merchant still holds pending or available funds")
+	// ErrMerchantHasSubMerchants is returned when closing a marketplace merchant whose
+	// sub-merchants are not all closed
+	ErrMerchantHasSubMerchants = errors.New("merchant still has sub-merchants that are not closed; close them first")
+	// ErrParentNotActive is returned when creating a sub-merchant under a parent that is
+	// not, or is no longer, active
+	ErrParentNotActive = errors.New("parent merchant is not active")
+)
+
+const merchantColumns = `id, name, email, business_name, country, status, kyc_status, kyc_tier, parent_id,
+	support_email, support_phone, website, logo_url, brand_color, statement_descriptor, kyc_review_required,
+	closed_at, anonymized_at, created_at, updated_at`
+
+func scanMerchant(row rowScanner) (*models.Merchant, error) {
+	merchant := &models.Merchant{}
+	err := row.Scan(
+		&merchant.ID,
+		&merchant.Name,
+		&merchant.Email,
+		&merchant.BusinessName,
+		&merchant.Country,
+		&merchant.Status,
+		&merchant.KYCStatus,
+		&merchant.KYCTier,
+		&merchant.ParentID,
+		&merchant.SupportEmail,
+		&merchant.SupportPhone,
+		&merchant.Website,
+		&merchant.LogoURL,
+		&merchant.BrandColor,
+		&merchant.StatementDescriptor,
+		&merchant.KYCReviewRequired,
+		&merchant.ClosedAt,
+		&merchant.AnonymizedAt,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return merchant, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 type MerchantRepository struct {
 	db *sql.DB
 }
@@ -42,54 +98,77 @@ func (r *MerchantRepository) GetDB() *sql.DB {
 	return r.db
 }
 
-// Create inserts a new merchant
+// Create inserts a new merchant. A sub-merchant is only inserted while its parent is
+// active: the parent row is share-locked until commit, so a concurrent close either
+// waits and then sees the new sub-merchant, or has already closed the parent and
+// Create returns ErrParentNotActive.
 func (r *MerchantRepository) Create(ctx context.Context, merchant *models.Merchant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if merchant.ParentID != nil {
+		var parentStatus models.MerchantStatus
+		err = tx.QueryRowContext(ctx, `
+			SELECT status FROM merchants WHERE id = $1 FOR SHARE
+		`, *merchant.ParentID).Scan(&parentStatus)
+		if err == sql.ErrNoRows {
+			return ErrMerchantNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if parentStatus != models.MerchantStatusActive {
+			return ErrParentNotActive
+		}
+	}
+
 	query := `
-		INSERT INTO merchants (name, email, business_name, country, status, kyc_status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO merchants (name, email, business_name, country, status, kyc_status, kyc_tier, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 	var id int
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		merchant.Name,
 		merchant.Email,
 		merchant.BusinessName,
 		merchant.Country,
 		merchant.Status,
 		merchant.KYCStatus,
+		merchant.KYCTier,
+		merchant.ParentID,
 		merchant.CreatedAt,
 		merchant.UpdatedAt,
 	).Scan(&id) // Retrieve the generated ID
 
-	if err == nil {
-		merchant.ID = id // Update the merchant object with the ID from DB
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrMerchantEmailTaken
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
 	}
-	return err
+	merchant.ID = id // Update the merchant object with the ID from DB
+	return nil
 }
 
 // GetByID retrieves a merchant by ID
 func (r *MerchantRepository) GetByID(ctx context.Context, id int) (*models.Merchant, error) {
 	query := `
-		SELECT id, name, email, business_name, country, status, kyc_status, created_at, updated_at
+		SELECT ` + merchantColumns + `
 		FROM merchants
 		WHERE id = $1
 	`
 
-	merchant := &models.Merchant{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&merchant.ID,
-		&merchant.Name,
-		&merchant.Email,
-		&merchant.BusinessName,
-		&merchant.Country,
-		&merchant.Status,
-		&merchant.KYCStatus,
-		&merchant.CreatedAt,
-		&merchant.UpdatedAt,
-	)
+	merchant, err := scanMerchant(r.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("merchant not found")
+		return nil, ErrMerchantNotFound
 	}
 
 	return merchant, err
@@ -98,26 +177,15 @@ func (r *MerchantRepository) GetByID(ctx context.Context, id int) (*models.Merch
 // GetByEmail retrieves a merchant by email
 func (r *MerchantRepository) GetByEmail(ctx context.Context, email string) (*models.Merchant, error) {
 	query := `
-		SELECT id, name, email, business_name, country, status, kyc_status, created_at, updated_at
+		SELECT ` + merchantColumns + `
 		FROM merchants
-		WHERE email = $1
+		WHERE lower(email) = lower($1)
 	`
 
-	merchant := &models.Merchant{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&merchant.ID,
-		&merchant.Name,
-		&merchant.Email,
-		&merchant.BusinessName,
-		&merchant.Country,
-		&merchant.Status,
-		&merchant.KYCStatus,
-		&merchant.CreatedAt,
-		&merchant.UpdatedAt,
-	)
+	merchant, err := scanMerchant(r.db.QueryRowContext(ctx, query, email))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("merchant not found")
+		return nil, ErrMerchantNotFound
 	}
 
 	return merchant, err
@@ -126,7 +194,7 @@ func (r *MerchantRepository) GetByEmail(ctx context.Context, email string) (*mod
 // List retrieves all merchants with optional filters
 func (r *MerchantRepository) List(ctx context.Context, status string, limit, offset int) ([]*models.Merchant, error) {
 	query := `
-		SELECT id, name, email, business_name, country, status, kyc_status, created_at, updated_at
+		SELECT ` + merchantColumns + `
 		FROM merchants
 	`
 
@@ -160,18 +228,7 @@ func (r *MerchantRepository) List(ctx context.Context, status string, limit, off
 
 	merchants := []*models.Merchant{}
 	for rows.Next() {
-		merchant := &models.Merchant{}
-		err := rows.Scan(
-			&merchant.ID,
-			&merchant.Name,
-			&merchant.Email,
-			&merchant.BusinessName,
-			&merchant.Country,
-			&merchant.Status,
-			&merchant.KYCStatus,
-			&merchant.CreatedAt,
-			&merchant.UpdatedAt,
-		)
+		merchant, err := scanMerchant(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -181,26 +238,38 @@ func (r *MerchantRepository) List(ctx context.Context, status string, limit, off
 	return merchants, rows.Err()
 }
 
-// Update updates a merchant's information
+// Update saves a merchant's profile fields. Status and KYC status have their own update paths.
 func (r *MerchantRepository) Update(ctx context.Context, merchant *models.Merchant) error {
 	query := `
 		UPDATE merchants
-		SET name = $2, business_name = $3, status = $4, kyc_status = $5, updated_at = $6
+		SET name = $2, email = $3, business_name = $4, country = $5,
+			support_email = $6, support_phone = $7, website = $8, logo_url = $9, brand_color = $10, statement_descriptor = $11,
+			kyc_review_required = $12, updated_at = $13
 		WHERE id = $1
 	`
 
 	merchant.UpdatedAt = time.Now()
 
 	result, err := r.db.ExecContext(ctx, query,
-		merchant.ID, // now int
+		merchant.ID,
 		merchant.Name,
+		merchant.Email,
 		merchant.BusinessName,
-		merchant.Status,
-		merchant.KYCStatus,
+		merchant.Country,
+		merchant.SupportEmail,
+		merchant.SupportPhone,
+		merchant.Website,
+		merchant.LogoURL,
+		merchant.BrandColor,
+		merchant.StatementDescriptor,
+		merchant.KYCReviewRequired,
 		merchant.UpdatedAt,
 	)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrMerchantEmailTaken
+		}
 		return err
 	}
 
@@ -210,42 +279,124 @@ func (r *MerchantRepository) Update(ctx context.Context, merchant *models.Mercha
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("merchant not found")
+		return ErrMerchantNotFound
 	}
 
 	return nil
 }
 
-// UpdateStatus updates a merchant's status
-func (r *MerchantRepository) UpdateStatus(ctx context.Context, id int, status models.MerchantStatus) error { // id changed to int
-	query := `
-		UPDATE merchants
-		SET status = $2, updated_at = $3
-		WHERE id = $1
-	`
-
-	result, err := r.db.ExecContext(ctx, query, id, status, time.Now())
+// TransitionStatus moves a merchant from one status to another and records the change
+// in merchant_status_history, in one transaction. It returns ErrStatusChanged if the
+// merchant is no longer in entry.FromStatus. Closing returns ErrMerchantHasFunds while
+// any balance holds funds and ErrMerchantHasSubMerchants while any sub-merchant is not
+// closed; the merchant row stays locked until commit, so balance writes and sub-merchant
+// inserts, which share-lock it, cannot land after the checks.
+func (r *MerchantRepository) TransitionStatus(ctx context.Context, entry *models.MerchantStatusHistory) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	result, err := tx.ExecContext(ctx, `
+		UPDATE merchants
+		SET status = $3, updated_at = $4,
+		    closed_at = CASE WHEN $3 = 'closed' THEN $4 ELSE closed_at END
+		WHERE id = $1 AND status = $2
+	`, entry.MerchantID, entry.FromStatus, entry.ToStatus, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
-
 	if rowsAffected == 0 {
-		return fmt.Errorf("merchant not found")
+		return ErrStatusChanged
 	}
 
-	return nil
+	if entry.ToStatus == models.MerchantStatusClosed {
+		var hasFunds bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM merchant_balances
+				WHERE merchant_id = $1 AND (pending_balance <> 0 OR available_balance <> 0)
+			)
+		`, entry.MerchantID).Scan(&hasFunds)
+		if err != nil {
+			return err
+		}
+		if hasFunds {
+			return ErrMerchantHasFunds
+		}
+
+		var hasSubMerchants bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM merchants
+				WHERE parent_id = $1 AND status <> 'closed'
+			)
+		`, entry.MerchantID).Scan(&hasSubMerchants)
+		if err != nil {
+			return err
+		}
+		if hasSubMerchants {
+			return ErrMerchantHasSubMerchants
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO merchant_status_history (merchant_id, from_status, to_status, reason_code, note, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, entry.MerchantID, entry.FromStatus, entry.ToStatus, entry.ReasonCode, entry.Note, entry.Actor, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListStatusHistory returns a merchant's status changes, newest first
+func (r *MerchantRepository) ListStatusHistory(ctx context.Context, merchantID, limit int) ([]*models.MerchantStatusHistory, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, merchant_id, from_status, to_status, reason_code, note, actor, created_at
+		FROM merchant_status_history
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, merchantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*models.MerchantStatusHistory{}
+	for rows.Next() {
+		entry := &models.MerchantStatusHistory{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.MerchantID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ReasonCode,
+			&entry.Note,
+			&entry.Actor,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
 }
 
-// UpdateKYCStatus updates a merchant's KYC status
+// UpdateKYCStatus updates a merchant's KYC status and clears any pending re-review flag
 func (r *MerchantRepository) UpdateKYCStatus(ctx context.Context, id int, kycStatus models.KYCStatus) error { // id changed to int
 	query := `
 		UPDATE merchants
-		SET kyc_status = $2, updated_at = $3
+		SET kyc_status = $2, kyc_review_required = false, updated_at = $3
 		WHERE id = $1
 	`
 
@@ -260,52 +411,67 @@ func (r *MerchantRepository) UpdateKYCStatus(ctx context.Context, id int, kycSta
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("merchant not found")
+		return ErrMerchantNotFound
 	}
 
 	return nil
 }
 
-// Delete deletes a merchant
-func (r *MerchantRepository) Delete(ctx context.Context, id int) error { // id changed to int
-	query := `DELETE FROM merchants WHERE id = $1`
+// ListClosedBefore returns closed merchants whose personal data has not yet been
+// anonymized and that were closed before cutoff
+func (r *MerchantRepository) ListClosedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*models.Merchant, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+merchantColumns+`
+		FROM merchants
+		WHERE status = 'closed' AND anonymized_at IS NULL AND closed_at < $1
+		ORDER BY closed_at
+		LIMIT $2
+	`, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merchants := []*models.Merchant{}
+	for rows.Next() {
+		merchant, err := scanMerchant(rows)
+		if err != nil {
+			return nil, err
+		}
+		merchants = append(merchants, merchant)
+	}
+	return merchants, rows.Err()
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+// Anonymize replaces a closed merchant's personal data with placeholders. The row itself
+// is kept so balances, payment links and status history still resolve.
+func (r *MerchantRepository) Anonymize(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE merchants
+		SET name = 'Anonymized merchant',
+		    email = 'anonymized+' || id || '@invalid.kodrapay',
+		    business_name = 'Anonymized merchant',
+		    support_email = '', support_phone = '', website = '', logo_url = '',
+		    anonymized_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'closed' AND anonymized_at IS NULL
+	`, id)
 	if err != nil {
 		return err
 	}
-
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
-
 	if rowsAffected == 0 {
-		return fmt.Errorf("merchant not found")
+		return ErrMerchantNotFound
 	}
-
 	return nil
 }
 
-// Count returns the total number of merchants
-func (r *MerchantRepository) Count(ctx context.Context, status string) (int, error) {
-	query := `SELECT COUNT(*) FROM merchants`
-
-	args := []interface{}{}
-	if status != "" {
-		query += " WHERE status = $1"
-		args = append(args, status)
-	}
-
-	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
-	return count, err
-}
-
 // ListByKYCStatus retrieves merchants by their KYC status
 func (r *MerchantRepository) ListByKYCStatus(ctx context.Context, kycStatus models.KYCStatus, limit, offset int) ([]*models.Merchant, error) {
 	query := `
-		SELECT id, name, email, business_name, country, status, kyc_status, created_at, updated_at
+		SELECT ` + merchantColumns + `
 		FROM merchants
 		WHERE kyc_status = $1
 	`
@@ -334,18 +500,7 @@ func (r *MerchantRepository) ListByKYCStatus(ctx context.Context, kycStatus mode
 
 	merchants := []*models.Merchant{}
 	for rows.Next() {
-		merchant := &models.Merchant{}
-		err := rows.Scan(
-			&merchant.ID, // now int
-			&merchant.Name,
-			&merchant.Email,
-			&merchant.BusinessName,
-			&merchant.Country,
-			&merchant.Status,
-			&merchant.KYCStatus,
-			&merchant.CreatedAt,
-			&merchant.UpdatedAt,
-		)
+		merchant, err := scanMerchant(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -355,14 +510,14 @@ func (r *MerchantRepository) ListByKYCStatus(ctx context.Context, kycStatus mode
 	return merchants, rows.Err()
 }
 
-// ListByKYCStatuses retrieves merchants by multiple KYC statuses
+// ListByKYCStatuses retrieves merchants by multiple KYC statuses; no statuses means all merchants
 func (r *MerchantRepository) ListByKYCStatuses(ctx context.Context, kycStatuses []models.KYCStatus, limit, offset int) ([]*models.Merchant, error) {
 	if len(kycStatuses) == 0 {
-		return []*models.Merchant{}, nil
+		return r.List(ctx, "", limit, offset)
 	}
 
 	query := `
-		SELECT id, name, email, business_name, country, status, kyc_status, created_at, updated_at
+		SELECT ` + merchantColumns + `
 		FROM merchants
 		WHERE kyc_status IN (`
 	
@@ -401,18 +556,7 @@ func (r *MerchantRepository) ListByKYCStatuses(ctx context.Context, kycStatuses
 
 	merchants := []*models.Merchant{}
 	for rows.Next() {
-		merchant := &models.Merchant{}
-		err := rows.Scan(
-			&merchant.ID, // now int
-			&merchant.Name,
-			&merchant.Email,
-			&merchant.BusinessName,
-			&merchant.Country,
-			&merchant.Status,
-			&merchant.KYCStatus,
-			&merchant.CreatedAt,
-			&merchant.UpdatedAt,
-		)
+		merchant, err := scanMerchant(rows)
 		if err != nil {
 			return nil, err
 		}
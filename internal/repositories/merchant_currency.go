@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+type MerchantCurrencyRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantCurrencyRepository(db *sql.DB) *MerchantCurrencyRepository {
+	return &MerchantCurrencyRepository{db: db}
+}
+
+// Enable adds a currency for a merchant. It reports false when the currency was already enabled.
+func (r *MerchantCurrencyRepository) Enable(ctx context.Context, mc *models.MerchantCurrency) (bool, error) {
+	query := `
+		INSERT INTO merchant_currencies (merchant_id, currency, is_default, enabled_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (merchant_id, currency) DO NOTHING
+	`
+	res, err := r.db.ExecContext(ctx, query, mc.MerchantID, mc.Currency, mc.IsDefault, mc.EnabledBy, mc.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ListByMerchant returns a merchant's enabled currencies, default currency first
+func (r *MerchantCurrencyRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.MerchantCurrency, error) {
+	query := `
+		SELECT merchant_id, currency, is_default, enabled_by, created_at
+		FROM merchant_currencies
+		WHERE merchant_id = $1
+		ORDER BY is_default DESC, created_at, currency
+	`
+	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []*models.MerchantCurrency
+	for rows.Next() {
+		mc := &models.MerchantCurrency{}
+		if err := rows.Scan(&mc.MerchantID, &mc.Currency, &mc.IsDefault, &mc.EnabledBy, &mc.CreatedAt); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, mc)
+	}
+	return currencies, rows.Err()
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// MerchantFilter narrows merchant searches. Zero-valued fields do not filter.
+type MerchantFilter struct {
+	Statuses    []string
+	KYCStatuses []string
+	Countries   []string
+	CreatedFrom *time.Time // inclusive
+	CreatedTo   *time.Time // exclusive
+	Query       string     // matched against name, email and business name
+	ParentID    *int       // only sub-merchants of this merchant
+}
+
+// MerchantCursor marks the last merchant of a page in (created_at, id) order
+type MerchantCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// where builds the WHERE clause for f, numbering placeholders after the existing args
+func (f MerchantFilter) where(args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(f.Statuses) > 0 {
+		add("status = ANY($%d)", pq.StringArray(f.Statuses))
+	}
+	if len(f.KYCStatuses) > 0 {
+		add("kyc_status = ANY($%d)", pq.StringArray(f.KYCStatuses))
+	}
+	if len(f.Countries) > 0 {
+		add("country = ANY($%d)", pq.StringArray(f.Countries))
+	}
+	if f.CreatedFrom != nil {
+		add("created_at >= $%d", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		add("created_at < $%d", *f.CreatedTo)
+	}
+	if f.ParentID != nil {
+		add("parent_id = $%d", *f.ParentID)
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		add("(name ILIKE $%[1]d OR email ILIKE $%[1]d OR business_name ILIKE $%[1]d)", "%"+escapeLike(q)+"%")
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Search returns up to limit merchants matching filter, newest first, starting after cursor
+func (r *MerchantRepository) Search(ctx context.Context, filter MerchantFilter, after *MerchantCursor, limit int) ([]*models.Merchant, error) {
+	where, args := filter.where(nil)
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		cond := fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+		if where == "" {
+			where = " WHERE " + cond
+		} else {
+			where += " AND " + cond
+		}
+	}
+	args = append(args, limit)
+
+	query := `SELECT ` + merchantColumns + ` FROM merchants` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merchants := []*models.Merchant{}
+	for rows.Next() {
+		merchant, err := scanMerchant(rows)
+		if err != nil {
+			return nil, err
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	return merchants, rows.Err()
+}
+
+// Count returns the number of merchants matching filter
+func (r *MerchantRepository) Count(ctx context.Context, filter MerchantFilter) (int, error) {
+	where, args := filter.where(nil)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM merchants`+where, args...).Scan(&count)
+	return count, err
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -108,25 +108,12 @@ func (r *PaymentLinkRepository) Update(ctx context.Context, link *models.Payment
 	return r.db.QueryRowContext(ctx, query, link.Status, link.ID).Scan(&link.UpdatedAt)
 }
 
-// Delete removes a payment link by ID, optionally scoping to merchant ownership.
+// Delete removes one of a merchant's payment links
 func (r *PaymentLinkRepository) Delete(ctx context.Context, id, merchantID int) error {
-	var (
-		res sql.Result
-		err error
-	)
-
-	if merchantID != 0 {
-		res, err = r.db.ExecContext(ctx, `
-			DELETE FROM payment_links
-			WHERE id = $1 AND merchant_id = $2
-		`, id, merchantID)
-	} else {
-		res, err = r.db.ExecContext(ctx, `
-			DELETE FROM payment_links
-			WHERE id = $1
-		`, id)
-	}
-
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM payment_links
+		WHERE id = $1 AND merchant_id = $2
+	`, id, merchantID)
 	if err != nil {
 		return err
 	}
@@ -139,3 +126,16 @@ func (r *PaymentLinkRepository) Delete(ctx context.Context, id, merchantID int)
 	}
 	return nil
 }
+
+// DeactivateByMerchant marks all of a merchant's active payment links inactive
+func (r *PaymentLinkRepository) DeactivateByMerchant(ctx context.Context, merchantID int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE payment_links
+		SET status = 'inactive', updated_at = NOW()
+		WHERE merchant_id = $1 AND status = 'active'
+	`, merchantID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -138,20 +138,20 @@ func NewSettlementConfigRepository(db *sql.DB) *SettlementConfigRepository {
 	return &SettlementConfigRepository{db: db}
 }
 
-// GetByMerchantID retrieves settlement config for a merchant
-func (r *SettlementConfigRepository) GetByMerchantID(ctx context.Context, merchantID int) (*models.SettlementConfig, error) {
+// GetByMerchantID retrieves a merchant's settlement config for a currency
+func (r *SettlementConfigRepository) GetByMerchantID(ctx context.Context, merchantID int, currency string) (*models.SettlementConfig, error) {
 	query := `
 		SELECT id, merchant_id, schedule_type, settlement_time, settlement_days,
 		       minimum_amount, auto_settle, settlement_delay_days, currency,
 		       created_at, updated_at
 		FROM settlement_configs
-		WHERE merchant_id = $1
+		WHERE merchant_id = $1 AND currency = $2
 	`
 
 	var sc models.SettlementConfig
 	var settlementDays pq.Int64Array
 
-	err := r.db.QueryRowContext(ctx, query, merchantID).Scan(
+	err := r.db.QueryRowContext(ctx, query, merchantID, currency).Scan(
 		&sc.ID, &sc.MerchantID, &sc.ScheduleType, &sc.SettlementTime,
 		&settlementDays, &sc.MinimumAmount, &sc.AutoSettle,
 		&sc.SettlementDelayDays, &sc.Currency, &sc.CreatedAt, &sc.UpdatedAt,
@@ -159,7 +159,7 @@ func (r *SettlementConfigRepository) GetByMerchantID(ctx context.Context, mercha
 
 	if err == sql.ErrNoRows {
 		// Return default settlement config if none exist
-		return r.CreateDefault(ctx, merchantID)
+		return r.CreateDefault(ctx, merchantID, currency)
 	}
 
 	if err != nil {
@@ -175,8 +175,8 @@ func (r *SettlementConfigRepository) GetByMerchantID(ctx context.Context, mercha
 	return &sc, nil
 }
 
-// CreateDefault creates default settlement config for a merchant
-func (r *SettlementConfigRepository) CreateDefault(ctx context.Context, merchantID int) (*models.SettlementConfig, error) {
+// CreateDefault creates a merchant's default settlement config for a currency
+func (r *SettlementConfigRepository) CreateDefault(ctx context.Context, merchantID int, currency string) (*models.SettlementConfig, error) {
 	query := `
 		INSERT INTO settlement_configs (
 			merchant_id, schedule_type, settlement_time, settlement_days,
@@ -195,7 +195,7 @@ func (r *SettlementConfigRepository) CreateDefault(ctx context.Context, merchant
 	err := r.db.QueryRowContext(
 		ctx, query,
 		merchantID, models.ScheduleTypeDaily, "09:00:00", defaultDays,
-		0, true, 2, "NGN",
+		0, true, 2, currency,
 	).Scan(
 		&sc.ID, &sc.MerchantID, &sc.ScheduleType, &sc.SettlementTime,
 		&settlementDays, &sc.MinimumAmount, &sc.AutoSettle,
@@ -215,7 +215,7 @@ func (r *SettlementConfigRepository) CreateDefault(ctx context.Context, merchant
 	return &sc, nil
 }
 
-// Update updates settlement config for a merchant
+// Update updates a merchant's settlement config for sc.Currency
 func (r *SettlementConfigRepository) Update(ctx context.Context, sc *models.SettlementConfig) error {
 	// Convert []int to pq.Int64Array
 	settlementDays := make(pq.Int64Array, len(sc.SettlementDays))
@@ -232,13 +232,13 @@ func (r *SettlementConfigRepository) Update(ctx context.Context, sc *models.Sett
 			auto_settle = $6,
 			settlement_delay_days = $7,
 			updated_at = NOW()
-		WHERE merchant_id = $1
+		WHERE merchant_id = $1 AND currency = $8
 	`
 
 	result, err := r.db.ExecContext(
 		ctx, query,
 		sc.MerchantID, sc.ScheduleType, sc.SettlementTime, settlementDays,
-		sc.MinimumAmount, sc.AutoSettle, sc.SettlementDelayDays,
+		sc.MinimumAmount, sc.AutoSettle, sc.SettlementDelayDays, sc.Currency,
 	)
 
 	if err != nil {
@@ -251,7 +251,7 @@ func (r *SettlementConfigRepository) Update(ctx context.Context, sc *models.Sett
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("settlement config not found for merchant: %d", sc.MerchantID)
+		return fmt.Errorf("%s settlement config not found for merchant: %d", sc.Currency, sc.MerchantID)
 	}
 
 	return nil
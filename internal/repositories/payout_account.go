@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+var (
+	ErrPayoutAccountNotFound = errors.New("payout account not found")
+	ErrPayoutAccountExists   = errors.New("this bank account is already registered")
+	// ErrPrimaryPayoutAccount blocks removing a primary account while others in its currency remain
+	ErrPrimaryPayoutAccount = errors.New("choose another primary payout account in this currency before removing this one")
+)
+
+const payoutAccountColumns = `id, merchant_id, bank_code, account_number, account_name, currency, is_primary, status,
+	verified_at, usable_from, created_at, updated_at`
+
+func scanPayoutAccount(row rowScanner) (*models.PayoutAccount, error) {
+	a := &models.PayoutAccount{}
+	err := row.Scan(
+		&a.ID,
+		&a.MerchantID,
+		&a.BankCode,
+		&a.AccountNumber,
+		&a.AccountName,
+		&a.Currency,
+		&a.IsPrimary,
+		&a.Status,
+		&a.VerifiedAt,
+		&a.UsableFrom,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+type PayoutAccountRepository struct {
+	db *sql.DB
+}
+
+func NewPayoutAccountRepository(db *sql.DB) *PayoutAccountRepository {
+	return &PayoutAccountRepository{db: db}
+}
+
+// Create stores a verified account. A merchant's first account in a currency becomes its
+// primary for that currency.
+func (r *PayoutAccountRepository) Create(ctx context.Context, a *models.PayoutAccount) error {
+	query := `
+		INSERT INTO merchant_payout_accounts (merchant_id, bank_code, account_number, account_name, currency, is_primary, status,
+			verified_at, usable_from, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5,
+			NOT EXISTS (SELECT 1 FROM merchant_payout_accounts WHERE merchant_id = $1 AND currency = $5 AND status = 'active' AND is_primary),
+			$6, $7, $8, $9, $10)
+		RETURNING id, is_primary
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		a.MerchantID,
+		a.BankCode,
+		a.AccountNumber,
+		a.AccountName,
+		a.Currency,
+		a.Status,
+		a.VerifiedAt,
+		a.UsableFrom,
+		a.CreatedAt,
+		a.UpdatedAt,
+	).Scan(&a.ID, &a.IsPrimary)
+	if isUniqueViolation(err) {
+		return ErrPayoutAccountExists
+	}
+	return err
+}
+
+func (r *PayoutAccountRepository) GetByID(ctx context.Context, merchantID, id int) (*models.PayoutAccount, error) {
+	query := `
+		SELECT ` + payoutAccountColumns + `
+		FROM merchant_payout_accounts
+		WHERE merchant_id = $1 AND id = $2 AND status = 'active'
+	`
+	a, err := scanPayoutAccount(r.db.QueryRowContext(ctx, query, merchantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPayoutAccountNotFound
+	}
+	return a, err
+}
+
+// GetPrimary returns the merchant's primary account for currency
+func (r *PayoutAccountRepository) GetPrimary(ctx context.Context, merchantID int, currency string) (*models.PayoutAccount, error) {
+	query := `
+		SELECT ` + payoutAccountColumns + `
+		FROM merchant_payout_accounts
+		WHERE merchant_id = $1 AND currency = $2 AND status = 'active' AND is_primary
+	`
+	a, err := scanPayoutAccount(r.db.QueryRowContext(ctx, query, merchantID, currency))
+	if err == sql.ErrNoRows {
+		return nil, ErrPayoutAccountNotFound
+	}
+	return a, err
+}
+
+func (r *PayoutAccountRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.PayoutAccount, error) {
+	query := `
+		SELECT ` + payoutAccountColumns + `
+		FROM merchant_payout_accounts
+		WHERE merchant_id = $1 AND status = 'active'
+		ORDER BY currency, is_primary DESC, created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*models.PayoutAccount
+	for rows.Next() {
+		a, err := scanPayoutAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// UpdateDetails replaces an account's bank details after re-verification, restarting
+// its cooling-off period
+func (r *PayoutAccountRepository) UpdateDetails(ctx context.Context, a *models.PayoutAccount) error {
+	query := `
+		UPDATE merchant_payout_accounts
+		SET bank_code = $3, account_number = $4, account_name = $5, currency = $6,
+			verified_at = $7, usable_from = $8, updated_at = $9
+		WHERE merchant_id = $1 AND id = $2 AND status = 'active'
+	`
+	res, err := r.db.ExecContext(ctx, query,
+		a.MerchantID,
+		a.ID,
+		a.BankCode,
+		a.AccountNumber,
+		a.AccountName,
+		a.Currency,
+		a.VerifiedAt,
+		a.UsableFrom,
+		a.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return ErrPayoutAccountExists
+	}
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, ErrPayoutAccountNotFound)
+}
+
+// SetPrimary makes id the merchant's only primary account in its currency
+func (r *PayoutAccountRepository) SetPrimary(ctx context.Context, merchantID, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merchant_payout_accounts
+		SET is_primary = false, updated_at = NOW()
+		WHERE merchant_id = $1 AND is_primary AND id <> $2
+		  AND currency = (SELECT currency FROM merchant_payout_accounts WHERE merchant_id = $1 AND id = $2)
+	`, merchantID, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE merchant_payout_accounts
+		SET is_primary = true, updated_at = NOW()
+		WHERE merchant_id = $1 AND id = $2 AND status = 'active'
+	`, merchantID, id)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(res, ErrPayoutAccountNotFound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Remove retires an account. A primary account can only be removed when it is the last
+// one in its currency.
+func (r *PayoutAccountRepository) Remove(ctx context.Context, merchantID, id int) error {
+	query := `
+		UPDATE merchant_payout_accounts a
+		SET status = 'removed', is_primary = false, updated_at = NOW()
+		WHERE a.merchant_id = $1 AND a.id = $2 AND a.status = 'active'
+		  AND (NOT a.is_primary OR NOT EXISTS (
+			SELECT 1 FROM merchant_payout_accounts o
+			WHERE o.merchant_id = $1 AND o.id <> $2 AND o.currency = a.currency AND o.status = 'active'
+		  ))
+	`
+	res, err := r.db.ExecContext(ctx, query, merchantID, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil || affected > 0 {
+		return err
+	}
+	// Nothing changed: the account is missing or is a primary with siblings in its currency
+	if _, err := r.GetByID(ctx, merchantID, id); err != nil {
+		return err
+	}
+	return ErrPrimaryPayoutAccount
+}
+
+// expectOneRow returns notFound when res affected no rows
+func expectOneRow(res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// AnonymizeByMerchant clears the bank details held on a merchant's payout accounts and
+// removes them, keeping the rows so past payouts still resolve
+func (r *PayoutAccountRepository) AnonymizeByMerchant(ctx context.Context, merchantID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_payout_accounts
+		SET account_number = '', account_name = '', is_primary = false, status = 'removed', updated_at = NOW()
+		WHERE merchant_id = $1
+	`, merchantID)
+	return err
+}
@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+var (
+	ErrTeamMemberNotFound = errors.New("team member not found")
+	ErrTeamMemberExists   = errors.New("this person is already on the team or has a pending invitation")
+	ErrInvitationInvalid  = errors.New("invitation is invalid, expired or already used")
+	ErrLastOwner          = errors.New("a merchant must keep at least one owner")
+)
+
+const teamMemberColumns = `id, merchant_id, email, user_id, role, status, invitation_token_hash, invitation_token_prefix,
+	invitation_expires_at, invited_by, accepted_at, revoked_at, created_at, updated_at`
+
+func scanTeamMember(row rowScanner) (*models.TeamMember, error) {
+	m := &models.TeamMember{}
+	var userID, tokenHash, tokenPrefix, invitedBy sql.NullString
+	err := row.Scan(
+		&m.ID,
+		&m.MerchantID,
+		&m.Email,
+		&userID,
+		&m.Role,
+		&m.Status,
+		&tokenHash,
+		&tokenPrefix,
+		&m.InvitationExpiresAt,
+		&invitedBy,
+		&m.AcceptedAt,
+		&m.RevokedAt,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		m.UserID = &userID.String
+	}
+	m.InvitationTokenHash = tokenHash.String
+	m.InvitationTokenPrefix = tokenPrefix.String
+	m.InvitedBy = invitedBy.String
+	return m, nil
+}
+
+type TeamMemberRepository struct {
+	db *sql.DB
+}
+
+func NewTeamMemberRepository(db *sql.DB) *TeamMemberRepository {
+	return &TeamMemberRepository{db: db}
+}
+
+// CreateInvitation stores a pending invitation. The unique index on merchant and
+// lower(email) for non-revoked members rejects duplicates.
+func (r *TeamMemberRepository) CreateInvitation(ctx context.Context, m *models.TeamMember) error {
+	query := `
+		INSERT INTO merchant_team_members (merchant_id, email, role, status, invitation_token_hash, invitation_token_prefix,
+			invitation_expires_at, invited_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		m.MerchantID,
+		m.Email,
+		m.Role,
+		m.Status,
+		m.InvitationTokenHash,
+		m.InvitationTokenPrefix,
+		m.InvitationExpiresAt,
+		m.InvitedBy,
+		m.CreatedAt,
+		m.UpdatedAt,
+	).Scan(&m.ID)
+	if isUniqueViolation(err) {
+		return ErrTeamMemberExists
+	}
+	return err
+}
+
+func (r *TeamMemberRepository) GetByID(ctx context.Context, merchantID, id int) (*models.TeamMember, error) {
+	query := `
+		SELECT ` + teamMemberColumns + `
+		FROM merchant_team_members
+		WHERE merchant_id = $1 AND id = $2
+	`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, merchantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTeamMemberNotFound
+	}
+	return m, err
+}
+
+// GetByInvitationPrefix returns the pending invitation whose token starts with prefix
+func (r *TeamMemberRepository) GetByInvitationPrefix(ctx context.Context, prefix string) (*models.TeamMember, error) {
+	query := `
+		SELECT ` + teamMemberColumns + `
+		FROM merchant_team_members
+		WHERE invitation_token_prefix = $1 AND status = 'invited'
+	`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, prefix))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	return m, err
+}
+
+// GetActiveByUser returns the active membership of userID on a merchant
+func (r *TeamMemberRepository) GetActiveByUser(ctx context.Context, merchantID int, userID string) (*models.TeamMember, error) {
+	query := `
+		SELECT ` + teamMemberColumns + `
+		FROM merchant_team_members
+		WHERE merchant_id = $1 AND user_id = $2 AND status = 'active'
+	`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, merchantID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrTeamMemberNotFound
+	}
+	return m, err
+}
+
+// ListByMerchant returns a merchant's active members and pending invitations
+func (r *TeamMemberRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.TeamMember, error) {
+	query := `
+		SELECT ` + teamMemberColumns + `
+		FROM merchant_team_members
+		WHERE merchant_id = $1 AND status <> 'revoked'
+		ORDER BY created_at
+	`
+	return r.list(ctx, query, merchantID)
+}
+
+// ListActiveByUser returns every merchant userID is an active member of
+func (r *TeamMemberRepository) ListActiveByUser(ctx context.Context, userID string) ([]*models.TeamMember, error) {
+	query := `
+		SELECT ` + teamMemberColumns + `
+		FROM merchant_team_members
+		WHERE user_id = $1 AND status = 'active'
+		ORDER BY merchant_id
+	`
+	return r.list(ctx, query, userID)
+}
+
+func (r *TeamMemberRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.TeamMember, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*models.TeamMember
+	for rows.Next() {
+		m, err := scanTeamMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AcceptInvitation binds userID to an unexpired invitation and activates the member.
+// The token hash is cleared so the invitation cannot be used twice.
+func (r *TeamMemberRepository) AcceptInvitation(ctx context.Context, id int, userID string, now time.Time) (*models.TeamMember, error) {
+	query := `
+		UPDATE merchant_team_members
+		SET user_id = $2, status = 'active', accepted_at = $3, updated_at = $3,
+			invitation_token_hash = NULL, invitation_token_prefix = NULL
+		WHERE id = $1 AND status = 'invited' AND invitation_expires_at > $3
+		RETURNING ` + teamMemberColumns
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, id, userID, now))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrTeamMemberExists
+	}
+	return m, err
+}
+
+// BootstrapOwner makes userID the owner of a merchant that has no active members yet,
+// which covers accounts created before teams existed. It returns ErrTeamMemberNotFound
+// when the merchant already has members.
+func (r *TeamMemberRepository) BootstrapOwner(ctx context.Context, merchantID int, userID string) (*models.TeamMember, error) {
+	var member *models.TeamMember
+	err := r.withMerchantLock(ctx, merchantID, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO merchant_team_members (merchant_id, email, user_id, role, status, accepted_at, created_at, updated_at)
+			SELECT m.id, m.email, $2, 'owner', 'active', NOW(), NOW(), NOW()
+			FROM merchants m
+			WHERE m.id = $1
+			  AND NOT EXISTS (SELECT 1 FROM merchant_team_members WHERE merchant_id = $1 AND status = 'active')
+			RETURNING ` + teamMemberColumns
+		m, err := scanTeamMember(tx.QueryRowContext(ctx, query, merchantID, userID))
+		if err == sql.ErrNoRows {
+			return ErrTeamMemberNotFound
+		}
+		member = m
+		return err
+	})
+	return member, err
+}
+
+// UpdateRole changes a member's role, refusing to demote the last active owner
+func (r *TeamMemberRepository) UpdateRole(ctx context.Context, merchantID, id int, role models.TeamRole) (*models.TeamMember, error) {
+	var member *models.TeamMember
+	err := r.withMerchantLock(ctx, merchantID, func(tx *sql.Tx) error {
+		if err := r.guardLastOwner(ctx, tx, merchantID, id, role == models.TeamRoleOwner); err != nil {
+			return err
+		}
+		query := `
+			UPDATE merchant_team_members
+			SET role = $3, updated_at = NOW()
+			WHERE merchant_id = $1 AND id = $2 AND status <> 'revoked'
+			RETURNING ` + teamMemberColumns
+		m, err := scanTeamMember(tx.QueryRowContext(ctx, query, merchantID, id, role))
+		if err == sql.ErrNoRows {
+			return ErrTeamMemberNotFound
+		}
+		member = m
+		return err
+	})
+	return member, err
+}
+
+// Revoke removes a member or cancels a pending invitation, refusing to remove the last active owner
+func (r *TeamMemberRepository) Revoke(ctx context.Context, merchantID, id int) (*models.TeamMember, error) {
+	var member *models.TeamMember
+	err := r.withMerchantLock(ctx, merchantID, func(tx *sql.Tx) error {
+		if err := r.guardLastOwner(ctx, tx, merchantID, id, false); err != nil {
+			return err
+		}
+		query := `
+			UPDATE merchant_team_members
+			SET status = 'revoked', revoked_at = NOW(), updated_at = NOW(),
+				invitation_token_hash = NULL, invitation_token_prefix = NULL
+			WHERE merchant_id = $1 AND id = $2 AND status <> 'revoked'
+			RETURNING ` + teamMemberColumns
+		m, err := scanTeamMember(tx.QueryRowContext(ctx, query, merchantID, id))
+		if err == sql.ErrNoRows {
+			return ErrTeamMemberNotFound
+		}
+		member = m
+		return err
+	})
+	return member, err
+}
+
+// guardLastOwner returns ErrLastOwner when member id is the merchant's only active
+// owner and the change would not leave them an owner
+func (r *TeamMemberRepository) guardLastOwner(ctx context.Context, tx *sql.Tx, merchantID, id int, staysOwner bool) error {
+	if staysOwner {
+		return nil
+	}
+	var isOwner bool
+	var owners int
+	err := tx.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(bool_or(id = $2), false),
+			COUNT(*)
+		FROM merchant_team_members
+		WHERE merchant_id = $1 AND role = 'owner' AND status = 'active'
+	`, merchantID, id).Scan(&isOwner, &owners)
+	if err != nil {
+		return err
+	}
+	if isOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// withMerchantLock runs fn in a transaction holding the merchant row lock, which
+// serialises team changes per merchant so the owner count cannot race
+func (r *TeamMemberRepository) withMerchantLock(ctx context.Context, merchantID int, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM merchants WHERE id = $1 FOR UPDATE`, merchantID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return ErrMerchantNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AnonymizeByMerchant replaces the emails and user links of a merchant's team members
+// with placeholders and revokes any remaining access
+func (r *TeamMemberRepository) AnonymizeByMerchant(ctx context.Context, merchantID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_team_members
+		SET email = 'anonymized+member' || id || '@invalid.kodrapay', user_id = NULL, invited_by = NULL,
+		    invitation_token_hash = NULL, invitation_token_prefix = NULL,
+		    status = 'revoked', revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+		WHERE merchant_id = $1
+	`, merchantID)
+	return err
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTwoFactorNotFound = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+)
+
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) GetByMerchantID(ctx context.Context, merchantID int) (*models.MerchantTwoFactor, error) {
+	query := `
+		SELECT merchant_id, secret, enabled, recovery_codes, last_used_step, failed_attempts, locked_until, enabled_at, created_at
+		FROM merchant_two_factor
+		WHERE merchant_id = $1
+	`
+	tf := &models.MerchantTwoFactor{}
+	err := r.db.QueryRowContext(ctx, query, merchantID).Scan(
+		&tf.MerchantID,
+		&tf.EncryptedSecret,
+		&tf.Enabled,
+		(*pq.StringArray)(&tf.RecoveryCodeHashes),
+		&tf.LastUsedStep,
+		&tf.FailedAttempts,
+		&tf.LockedUntil,
+		&tf.EnabledAt,
+		&tf.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+// SavePending stores a new, not yet activated secret. An enabled enrollment is never replaced.
+func (r *TwoFactorRepository) SavePending(ctx context.Context, merchantID int, encryptedSecret string) error {
+	query := `
+		INSERT INTO merchant_two_factor (merchant_id, secret, enabled, recovery_codes, last_used_step, created_at)
+		VALUES ($1, $2, false, '{}', 0, NOW())
+		ON CONFLICT (merchant_id) DO UPDATE
+		SET secret = EXCLUDED.secret, recovery_codes = '{}', last_used_step = 0, failed_attempts = 0, locked_until = NULL, created_at = NOW()
+		WHERE merchant_two_factor.enabled = false
+	`
+	res, err := r.db.ExecContext(ctx, query, merchantID, encryptedSecret)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// Enable activates a pending enrollment with its recovery codes
+func (r *TwoFactorRepository) Enable(ctx context.Context, merchantID int, step int64, recoveryCodeHashes []string) error {
+	query := `
+		UPDATE merchant_two_factor
+		SET enabled = true, enabled_at = NOW(), last_used_step = $2, recovery_codes = $3
+		WHERE merchant_id = $1 AND enabled = false
+	`
+	res, err := r.db.ExecContext(ctx, query, merchantID, step, pq.StringArray(recoveryCodeHashes))
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTwoFactorNotFound
+	}
+	return nil
+}
+
+// ClaimStep records that a TOTP step was used. It reports false when the step, or a
+// later one, was already used, so a code cannot be replayed.
+func (r *TwoFactorRepository) ClaimStep(ctx context.Context, merchantID int, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_two_factor
+		SET last_used_step = $2
+		WHERE merchant_id = $1 AND last_used_step < $2
+	`, merchantID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// CountAttempt records a code check before it happens, so concurrent guesses cannot
+// outrun the limit. The maxAttempts-th attempt since the last success locks checks for
+// lockout unless ResetAttempts clears it. It reports false while checks are locked.
+func (r *TwoFactorRepository) CountAttempt(ctx context.Context, merchantID, maxAttempts int, lockout time.Duration) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_two_factor
+		SET failed_attempts = CASE WHEN locked_until IS NULL THEN failed_attempts ELSE 0 END + 1,
+			locked_until = CASE
+				WHEN CASE WHEN locked_until IS NULL THEN failed_attempts ELSE 0 END + 1 >= $2
+				THEN NOW() + make_interval(secs => $3)
+			END
+		WHERE merchant_id = $1 AND (locked_until IS NULL OR locked_until <= NOW())
+	`, merchantID, maxAttempts, lockout.Seconds())
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// ResetAttempts clears the attempt count and any lockout after a correct code
+func (r *TwoFactorRepository) ResetAttempts(ctx context.Context, merchantID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_two_factor
+		SET failed_attempts = 0, locked_until = NULL
+		WHERE merchant_id = $1
+	`, merchantID)
+	return err
+}
+
+// ConsumeRecoveryCode removes a recovery code hash. It reports false when the code was already used.
+func (r *TwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, merchantID int, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE merchant_two_factor
+		SET recovery_codes = array_remove(recovery_codes, $2)
+		WHERE merchant_id = $1 AND $2 = ANY(recovery_codes)
+	`, merchantID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Delete removes a merchant's enrollment, disabling two-factor confirmation
+func (r *TwoFactorRepository) Delete(ctx context.Context, merchantID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM merchant_two_factor WHERE merchant_id = $1`, merchantID)
+	return err
+}
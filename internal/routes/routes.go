@@ -1,20 +1,35 @@
 package routes
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/skip"
+	"github.com/kodra-pay/merchant-service/internal/auth"
 	"github.com/kodra-pay/merchant-service/internal/clients"
+	"github.com/kodra-pay/merchant-service/internal/config"
 	"github.com/kodra-pay/merchant-service/internal/handlers"
+	"github.com/kodra-pay/merchant-service/internal/middleware"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/ratelimit"
+	"github.com/kodra-pay/merchant-service/internal/replay"
 	"github.com/kodra-pay/merchant-service/internal/repositories"
 	"github.com/kodra-pay/merchant-service/internal/services"
+	"github.com/redis/go-redis/v9"
 )
 
-func Register(app *fiber.App, serviceName string) {
+// Register wires up the service and starts its background jobs. The returned function
+// stops the jobs and waits for them to finish, flushing buffered API key usage; call it
+// once the app has shut down.
+func Register(app *fiber.App, cfg config.Config) (stopJobs func()) {
 	// Health check
-	health := handlers.NewHealthHandler(serviceName)
+	health := handlers.NewHealthHandler(cfg.ServiceName)
 	health.Register(app)
 
 	// Get database URL from environment
@@ -47,28 +62,358 @@ func Register(app *fiber.App, serviceName string) {
 	settlementConfigRepo := repositories.NewSettlementConfigRepository(db)
 	paymentLinkRepo := repositories.NewPaymentLinkRepository(db)
 	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	apiKeyUsageRepo := repositories.NewAPIKeyUsageRepository(db)
 	kycSubmissionRepo := repositories.NewKYCSubmissionRepository(db)
 	balanceRepo := repositories.NewBalanceRepository(db)
+	adminRepo := repositories.NewAdminRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	teamMemberRepo := repositories.NewTeamMemberRepository(db)
+	payoutAccountRepo := repositories.NewPayoutAccountRepository(db)
+	merchantCurrencyRepo := repositories.NewMerchantCurrencyRepository(db)
+
+	// API keys are hashed with a versioned server-side pepper
+	apiKeyHasher, err := models.NewAPIKeyHasher(cfg.APIKeyPeppers, cfg.APIKeyPepperVersion)
+	if err != nil {
+		log.Fatalf("Invalid API key pepper configuration: %v", err)
+	}
+	if !apiKeyHasher.Peppered() {
+		if !cfg.AllowUnpepperedAPIKeys {
+			log.Fatalf("API_KEY_PEPPERS is not set; set INSECURE_ALLOW_UNPEPPERED_API_KEYS=true to run without a pepper in development")
+		}
+		log.Printf("WARNING: API_KEY_PEPPERS is not set; API keys are hashed without a pepper")
+	}
 
 	// Initialize services
-	merchantService := services.NewMerchantService(merchantRepo, apiKeyRepo, settlementConfigRepo, walletLedgerClient)
-	kycService := services.NewKYCService(merchantRepo, kycSubmissionRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, apiKeyUsageRepo, merchantRepo, apiKeyHasher)
+	merchantService := services.NewMerchantService(merchantRepo, apiKeyService, settlementConfigRepo, balanceRepo, paymentLinkRepo, merchantCurrencyRepo, walletLedgerClient)
+	kycService := services.NewKYCService(merchantRepo, kycSubmissionRepo, apiKeyService)
 	paymentOptionsService := services.NewPaymentOptionsService(paymentOptionsRepo)
 	settlementConfigService := services.NewSettlementConfigService(settlementConfigRepo)
-	paymentLinkService := services.NewPaymentLinkService(paymentLinkRepo)
-	balanceService := services.NewBalanceService(balanceRepo)
+	paymentLinkService := services.NewPaymentLinkService(paymentLinkRepo, merchantRepo)
+	payoutAccountService := services.NewPayoutAccountService(payoutAccountRepo, merchantRepo, kycSubmissionRepo, newBankAccountResolver(cfg), cfg.PayoutAccountCoolingOff)
+	balanceService := services.NewBalanceService(balanceRepo, payoutAccountService)
+	adminService := services.NewAdminService(adminRepo, apiKeyHasher)
+	if cfg.AdminBootstrapEmail != "" || cfg.AdminBootstrapToken != "" {
+		if _, err := adminService.Bootstrap(context.Background(), cfg.AdminBootstrapEmail, cfg.AdminBootstrapToken); err != nil {
+			log.Fatalf("Failed to bootstrap super admin: %v", err)
+		}
+	}
+	teamService := services.NewTeamService(teamMemberRepo, apiKeyHasher, cfg.TeamInvitationTTL)
+
+	// Two-factor confirmation for sensitive actions
+	var stepUpKeys *auth.StepUpKeys
+	if cfg.TwoFactorSecret != "" {
+		stepUpKeys, err = auth.NewStepUpKeys(cfg.TwoFactorSecret, cfg.TwoFactorConfirmationTTL)
+		if err != nil {
+			log.Fatalf("Invalid two-factor configuration: %v", err)
+		}
+	} else {
+		log.Printf("WARNING: TWO_FACTOR_SECRET is not set; two-factor enrollment is unavailable")
+	}
+	replayStore := newReplayStore(cfg)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, merchantRepo, stepUpKeys, apiKeyHasher, replayStore, cfg.TwoFactorMaxAttempts, cfg.TwoFactorLockout)
+
+	// Background jobs run until stopJobs cancels them
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	runJob := func(run func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			run(jobsCtx)
+		}()
+	}
+
+	// Resolve merchant API keys before any merchant-facing route runs
+	// API key usage is buffered and written out in batches
+	apiKeyUsageRecorder := services.NewAPIKeyUsageRecorder(apiKeyRepo, apiKeyUsageRepo, cfg.APIKeyUsageFlushInterval)
+	runJob(apiKeyUsageRecorder.Run)
+
+	// Closed merchants' personal data is anonymized once the retention period ends
+	retentionJob := services.NewMerchantRetentionJob(merchantRepo, kycSubmissionRepo, payoutAccountRepo, teamMemberRepo, time.Duration(cfg.MerchantRetentionDays)*24*time.Hour, cfg.RetentionJobInterval)
+	runJob(retentionJob.Run)
+
+	// Every caller is also limited by IP ahead of authentication, so guessing API keys,
+	// admin tokens or sessions is throttled even though each guess fails
+	rateLimitStore := newRateLimitStore(cfg)
+	ipRateLimiter := middleware.NewRateLimitMiddleware(rateLimitStore, rateLimits(cfg), "ip", func(*fiber.Ctx) string { return "ip" })
+	app.Use(skip.New(ipRateLimiter.HandleByIP, isInternalRoute))
+
+	apiKeyAuth := middleware.NewAPIKeyAuthMiddleware(apiKeyService, apiKeyUsageRecorder)
+	app.Use(apiKeyAuth.Authenticate)
+
+	// Back-office admins authenticate with adm_ tokens
+	adminAuth := middleware.NewAdminAuthMiddleware(adminService)
+	app.Use(adminAuth.Authenticate)
+
+	// Dashboard users authenticate with JWTs issued by the auth service
+	if cfg.SessionJWKS != "" {
+		if cfg.SessionIssuer == "" || cfg.SessionAudience == "" {
+			log.Fatalf("SESSION_ISSUER and SESSION_AUDIENCE must be set when SESSION_JWKS is set")
+		}
+		keySet, err := auth.NewKeySet(context.Background(), cfg.SessionJWKS, cfg.SessionJWKSTTL)
+		if err != nil {
+			log.Fatalf("Failed to load session JWKS: %v", err)
+		}
+		sessionAuth := middleware.NewSessionAuthMiddleware(auth.NewSessionVerifier(keySet, cfg.SessionIssuer, cfg.SessionAudience, cfg.SessionMerchantClaim), teamService)
+		app.Use(sessionAuth.Authenticate)
+	} else {
+		log.Printf("WARNING: SESSION_JWKS is not set; dashboard session tokens will not be accepted")
+	}
+
+	// Rate limiting is keyed by the authenticated caller, so it runs after authentication.
+	// /internal callers are limited separately once service auth has identified them.
+	rateLimiter := middleware.NewRateLimitMiddleware(rateLimitStore, rateLimits(cfg), "default", rateLimitGroup)
+	app.Use(skip.New(rateLimiter.Handle, isInternalRoute))
+
+	// Merchants act on themselves and on their marketplace sub-merchants
+	merchantAccess := middleware.NewMerchantAccess(merchantService)
 
 	// Initialize handlers
 	merchantHandler := handlers.NewMerchantHandler(merchantService)
-	kycHandler := handlers.NewKYCHandler(merchantService, kycService)
-	paymentOptionsHandler := handlers.NewPaymentOptionsHandler(paymentOptionsService, settlementConfigService)
-	paymentLinkHandler := handlers.NewPaymentLinkHandler(paymentLinkService)
-	balanceHandler := handlers.NewBalanceHandler(balanceService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	kycHandler := handlers.NewKYCHandler(merchantService, kycService, merchantAccess)
+	paymentOptionsHandler := handlers.NewPaymentOptionsHandler(paymentOptionsService, settlementConfigService, merchantService)
+	paymentLinkHandler := handlers.NewPaymentLinkHandler(paymentLinkService, merchantAccess)
+	balanceHandler := handlers.NewBalanceHandler(balanceService, merchantService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	teamHandler := handlers.NewTeamHandler(teamService)
+	payoutAccountHandler := handlers.NewPayoutAccountHandler(payoutAccountService)
+
+	// Scope and role guards run ahead of the handlers they protect
+	registerAdminRoles(app)
+	registerAPIKeyScopes(app)
+	registerOwnershipChecks(app, merchantAccess)
+	registerTeamRoles(app)
+	registerConfirmationChecks(app, twoFactorService)
+
+	// Retried signups with the same Idempotency-Key replay the original response
+	app.Post("/merchants", middleware.Idempotency(idempotencyRepo, "merchants.create", 24*time.Hour))
+	app.Use(middleware.RestrictUnscopedKeys)
+
+	// /internal routes only accept requests signed by known services
+	if len(cfg.InternalServiceSecrets) == 0 {
+		log.Printf("WARNING: INTERNAL_SERVICE_SECRETS is not set; all /internal requests will be rejected")
+	}
+	serviceAuth := middleware.NewServiceAuthMiddleware(cfg.InternalServiceSecrets, cfg.InternalAuthMaxSkew, replayStore)
+	internalRateLimiter := middleware.NewRateLimitMiddleware(rateLimitStore, rateLimits(cfg), "internal", func(*fiber.Ctx) string { return "internal" })
+	app.Use("/internal", serviceAuth.Require, internalRateLimiter.Handle)
 
 	// Register routes
 	merchantHandler.Register(app)
+	apiKeyHandler.Register(app)
 	kycHandler.Register(app)
 	paymentOptionsHandler.Register(app)
 	paymentLinkHandler.Register(app)
 	balanceHandler.Register(app)
+	adminHandler.Register(app)
+	twoFactorHandler.Register(app)
+	teamHandler.Register(app)
+	payoutAccountHandler.Register(app)
+
+	return func() {
+		cancelJobs()
+		jobs.Wait()
+	}
+}
+
+// newBankAccountResolver picks the account-name verification backend. There is no default,
+// so a deployment never falls back to the fake resolver by accident.
+func newBankAccountResolver(cfg config.Config) clients.BankAccountResolver {
+	switch cfg.BankAccountResolver {
+	case "":
+		log.Fatalf("BANK_ACCOUNT_RESOLVER is not set; set it to fake for local development")
+		return nil
+	case "fake":
+		log.Printf("WARNING: BANK_ACCOUNT_RESOLVER is fake; payout account names are not verified with a bank")
+		return clients.NewFakeBankAccountResolver()
+	default:
+		log.Fatalf("Unknown BANK_ACCOUNT_RESOLVER %q", cfg.BankAccountResolver)
+		return nil
+	}
+}
+
+// registerTeamRoles declares the team roles dashboard users need on merchant-scoped
+// routes. Owners pass every check; routes not listed are open to every member.
+func registerTeamRoles(app *fiber.App) {
+	manage := middleware.RequireTeamRole(models.TeamRoleAdmin)
+	developers := middleware.RequireTeamRole(models.TeamRoleAdmin, models.TeamRoleDeveloper)
+	finance := middleware.RequireTeamRole(models.TeamRoleAdmin, models.TeamRoleFinance)
+
+	app.Patch("/merchants/:id<int>", manage)
+	app.Post("/merchants/:id<int>/close", middleware.RequireTeamRole(models.TeamRoleOwner))
+	app.Put("/merchants/:id<int>/payment-options", manage)
+	app.Post("/merchants/:id<int>/2fa/enroll", manage)
+	app.Post("/merchants/:id<int>/2fa/activate", manage)
+	app.Post("/merchants/:id<int>/2fa/disable", manage)
+	app.Post("/merchants/:id<int>/sub-merchants", manage)
+	app.Post("/merchants/:id<int>/team/*", manage)
+	app.Put("/merchants/:id<int>/team/*", manage)
+	app.Delete("/merchants/:id<int>/team/*", manage)
+	app.Post("/kyc/submit", manage)
+
+	app.All("/merchants/:id<int>/api-keys", developers)
+	app.All("/merchants/:id<int>/api-keys/*", developers)
+	app.Post("/payment-links", developers)
+	app.Delete("/payment-links/:id", developers)
+
+	app.Get("/merchants/:id<int>/balance", finance)
+	app.All("/merchants/:id<int>/settlement-config", finance)
+	app.All("/merchants/:id<int>/payout-accounts/*", finance)
+}
+
+// registerConfirmationChecks lists the actions that need a fresh two-factor
+// confirmation from merchants who have enabled it
+func registerConfirmationChecks(app *fiber.App, twoFactor *services.TwoFactorService) {
+	app.Post("/merchants/:id<int>/api-keys/rotate", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyRotate))
+	app.Post("/merchants/:id<int>/api-keys/test", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyIssue))
+	app.Post("/merchants/:id<int>/api-keys/live", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyIssue))
+	app.Post("/merchants/:id<int>/api-keys/restricted", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyRestricted)) // Scopes can include settlement_config:write
+	app.Put("/merchants/:id<int>/api-keys/:key_id/ip-allowlist", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyIPAllowlist))
+	app.Post("/merchants/:id<int>/api-keys/:key_id/expire", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyRevoke))
+	app.Delete("/merchants/:id<int>/api-keys/:key_id", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmAPIKeyRevoke))
+	app.Put("/merchants/:id<int>/settlement-config", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmSettlementConfig))
+	app.Post("/merchants/:id<int>/close", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmMerchantClose))
+	app.Put("/merchants/:id<int>/payout-accounts/*", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmPayoutAccounts))
+	app.Post("/merchants/:id<int>/payout-accounts/*", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmPayoutAccounts)) // Also matches POST /payout-accounts
+	app.Delete("/merchants/:id<int>/payout-accounts/*", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmPayoutAccounts))
+	app.Post("/merchants/:id<int>/2fa/disable", middleware.RequireConfirmation(twoFactor, "id", models.ConfirmTwoFactorDisable))
+}
+
+// registerAdminRoles declares the admin roles each back-office route requires.
+// Super admins pass every check.
+func registerAdminRoles(app *fiber.App) {
+	app.Get("/merchants", middleware.RequireRole(models.AdminRoleOps, models.AdminRoleKYCReviewer, models.AdminRoleFinance))
+	app.Get("/merchants/kyc", middleware.RequireRole(models.AdminRoleKYCReviewer, models.AdminRoleOps))
+	app.Put("/merchants/:id/status", middleware.RequireRole(models.AdminRoleOps))
+	app.Get("/merchants/:id/status-history", middleware.RequireRole(models.AdminRoleOps))
+	app.Put("/merchants/:id/kyc-status", middleware.RequireRole(models.AdminRoleKYCReviewer))
+	app.Post("/merchants/:id/currencies", middleware.RequireRole(models.AdminRoleOps, models.AdminRoleFinance))
+
+	app.Post("/kyc/update", middleware.RequireRole(models.AdminRoleKYCReviewer))
+	app.Get("/kyc/pending", middleware.RequireRole(models.AdminRoleKYCReviewer))
+
+	app.Use("/admins", middleware.RequireRole(models.AdminRoleSuperAdmin))
+}
+
+// registerOwnershipChecks limits merchant-scoped routes to the owning merchant and admins.
+// A parent marketplace merchant may also read its sub-merchants and manage their
+// settlement config and balances. Admins may read any merchant but only write through
+// the routes listed here for their role. Handlers for resources addressed by their own
+// ID check ownership after loading them.
+func registerOwnershipChecks(app *fiber.App, access *middleware.MerchantAccess) {
+	app.Patch("/merchants/:id<int>", middleware.AllowAdminWrite(models.AdminRoleOps))
+	app.Put("/merchants/:id<int>/status", middleware.AllowAdminWrite(models.AdminRoleOps))
+	app.Post("/merchants/:id<int>/close", middleware.AllowAdminWrite(models.AdminRoleOps))
+	app.Put("/merchants/:id<int>/kyc-status", middleware.AllowAdminWrite(models.AdminRoleKYCReviewer))
+	app.Post("/merchants/:id<int>/currencies", middleware.AllowAdminWrite(models.AdminRoleOps, models.AdminRoleFinance))
+	app.Put("/merchants/:id<int>/payment-options", middleware.AllowAdminWrite(models.AdminRoleOps))
+	app.Put("/merchants/:id<int>/settlement-config", middleware.AllowAdminWrite(models.AdminRoleOps, models.AdminRoleFinance))
+
+	app.Get("/merchants/:id<int>", middleware.AllowParentAccess)
+	app.Get("/merchants/:id<int>/balance", middleware.AllowParentAccess)
+	app.Get("/merchants/:id<int>/settlement-config", middleware.AllowParentAccess)
+	app.Put("/merchants/:id<int>/settlement-config", middleware.AllowParentAccess)
+	app.Get("/kyc/status/:merchant_id", middleware.AllowParentAccess) // Parents submit KYC for sub-merchants
+
+	app.All("/merchants/:id<int>", access.RequireMerchantAccess("id"))
+	app.All("/merchants/:id<int>/*", access.RequireMerchantAccess("id"))
+	app.Get("/kyc/status/:merchant_id", access.RequireMerchantAccess("merchant_id"))
+	app.Post("/kyc/submit", middleware.RequirePrincipal)
+	app.Post("/merchants/:id<int>/api-keys/test", middleware.RequireMerchant)
+	app.Post("/merchants/:id<int>/api-keys/live", middleware.RequireMerchant)
+	app.Post("/merchants/:id<int>/api-keys/rotate", middleware.RequireMerchant)
+	app.Post("/merchants/:id<int>/api-keys/restricted", middleware.RequireMerchant)
+	app.Use("/payment-links", middleware.RequirePrincipal)
+}
+
+// registerAPIKeyScopes declares the scope each merchant-facing route requires when
+// called with a publishable or restricted API key. Secret keys hold every scope.
+func registerAPIKeyScopes(app *fiber.App) {
+	app.Get("/merchants/me", middleware.RequireScope(models.ScopeMerchantRead))
+	app.Get("/merchant/me", middleware.RequireScope(models.ScopeMerchantRead))
+	app.Get("/merchants/:id<int>", middleware.RequireScope(models.ScopeMerchantRead))
+
+	app.Post("/payment-links", middleware.RequireScope(models.ScopePaymentLinksWrite))
+	app.Get("/payment-links/:id", middleware.RequireScope(models.ScopePaymentLinksRead))
+	app.Delete("/payment-links/:id", middleware.RequireScope(models.ScopePaymentLinksWrite))
+	app.Get("/merchants/:merchant_id/payment-links", middleware.RequireScope(models.ScopePaymentLinksRead))
+
+	app.Get("/merchants/:id/balance", middleware.RequireScope(models.ScopeBalanceRead))
+
+	app.Post("/kyc/submit", middleware.RequireScope(models.ScopeKYCWrite))
+	app.Get("/kyc/status/:merchant_id", middleware.RequireScope(models.ScopeKYCRead))
+	app.Get("/kyc/requirements", middleware.RequireScope(models.ScopeKYCRead))
+
+	app.Get("/merchants/:id/payment-options", middleware.RequireScope(models.ScopePaymentOptionsRead))
+	app.Put("/merchants/:id/payment-options", middleware.RequireScope(models.ScopePaymentOptionsWrite))
+	app.Get("/merchants/:id/settlement-config", middleware.RequireScope(models.ScopeSettlementConfigRead))
+	app.Put("/merchants/:id/settlement-config", middleware.RequireScope(models.ScopeSettlementConfigWrite))
+
+	app.Post("/merchants/:id/sub-merchants", middleware.RequireScope(models.ScopeSubMerchantsWrite))
+	app.Get("/merchants/:id/sub-merchants", middleware.RequireScope(models.ScopeSubMerchantsRead))
+	app.Get("/merchants/:id/payout-accounts", middleware.RequireScope(models.ScopePayoutAccountsRead))
+}
+
+// defaultRateLimits are requests per minute for each route group
+var defaultRateLimits = map[string]int{
+	"default":       300,
+	"payment_links": 120,
+	"kyc":           20,
+	"api_keys":      30,
+	"internal":      6000, // Per calling service
+	"ip":            1200, // Per client IP, counted before authentication
+}
+
+func rateLimits(cfg config.Config) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit, len(defaultRateLimits))
+	for group, perMinute := range defaultRateLimits {
+		limits[group] = ratelimit.PerMinute(perMinute)
+	}
+	for group, value := range cfg.RateLimits {
+		perMinute, err := strconv.Atoi(value)
+		if err != nil || perMinute <= 0 {
+			log.Printf("Ignoring invalid rate limit %q for group %s", value, group)
+			continue
+		}
+		limits[group] = ratelimit.PerMinute(perMinute)
+	}
+	return limits
+}
+
+func newRateLimitStore(cfg config.Config) ratelimit.Store {
+	if cfg.RateLimitStore == "redis" {
+		client := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+		return ratelimit.NewRedisStore(client, cfg.ServiceName+":ratelimit:")
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+func newReplayStore(cfg config.Config) replay.Store {
+	if cfg.ReplayStore == "redis" {
+		client := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+		return replay.NewRedisStore(client, cfg.ServiceName+":replay:")
+	}
+	return replay.NewMemoryStore()
+}
+
+func isInternalRoute(c *fiber.Ctx) bool {
+	return strings.HasPrefix(c.Path(), "/internal/")
+}
+
+// rateLimitGroup picks the rate limit bucket for a request
+func rateLimitGroup(c *fiber.Ctx) string {
+	path := c.Path()
+	switch {
+	case strings.HasPrefix(path, "/payment-links"), strings.HasSuffix(path, "/payment-links"):
+		return "payment_links"
+	case strings.HasPrefix(path, "/kyc/"):
+		return "kyc"
+	case strings.Contains(path, "/api-keys"):
+		return "api_keys"
+	default:
+		return "default"
+	}
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	ErrInvalidAdminToken = errors.New("invalid admin token")
+	ErrAdminInactive     = errors.New("admin account is disabled")
+)
+
+// AdminService authenticates and manages back-office admins
+type AdminService struct {
+	repo   *repositories.AdminRepository
+	hasher *models.APIKeyHasher
+}
+
+func NewAdminService(repo *repositories.AdminRepository, hasher *models.APIKeyHasher) *AdminService {
+	return &AdminService{repo: repo, hasher: hasher}
+}
+
+// Authenticate resolves an adm_ bearer token to an active admin
+func (s *AdminService) Authenticate(ctx context.Context, token string) (*models.Admin, error) {
+	if !models.LooksLikeAdminToken(token) || len(token) <= models.APIKeyPrefixLength {
+		return nil, ErrInvalidAdminToken
+	}
+
+	admin, err := s.repo.GetByTokenPrefix(ctx, token[:models.APIKeyPrefixLength])
+	if err != nil {
+		if errors.Is(err, repositories.ErrAdminNotFound) {
+			return nil, ErrInvalidAdminToken
+		}
+		return nil, err
+	}
+
+	matched, needsRehash := s.hasher.Verify(token, admin.TokenHash)
+	if !matched {
+		return nil, ErrInvalidAdminToken
+	}
+	if !admin.IsActive {
+		return nil, ErrAdminInactive
+	}
+
+	if needsRehash {
+		newHash := s.hasher.Hash(token)
+		if err := s.repo.UpdateHash(ctx, admin.ID, admin.TokenHash, newHash); err != nil {
+			log.Printf("Failed to rehash admin token %d: %v", admin.ID, err)
+		} else {
+			admin.TokenHash = newHash
+		}
+	}
+
+	return admin, nil
+}
+
+// Create registers an admin and returns its token, which is not retrievable again
+func (s *AdminService) Create(ctx context.Context, req dto.AdminCreateRequest) (*dto.AdminResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		return nil, &ValidationError{Field: "email", Message: "is required"}
+	}
+	roles, err := parseAdminRoles(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := &models.Admin{
+		Email:     email,
+		Name:      strings.TrimSpace(req.Name),
+		Roles:     roles,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+	token, err := models.GenerateAdminToken(admin, s.hasher)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, admin); err != nil {
+		return nil, err
+	}
+
+	resp := adminToResponse(admin)
+	resp.Token = token
+	return &resp, nil
+}
+
+// minBootstrapTokenLength is adm_ followed by at least 32 random characters
+const minBootstrapTokenLength = 36
+
+// Bootstrap creates the first super admin from an operator-supplied adm_ token so the
+// back office can be reached after a fresh deploy. It does nothing once an active super
+// admin exists and reports whether one was created.
+func (s *AdminService) Bootstrap(ctx context.Context, email, token string) (bool, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false, &ValidationError{Field: "email", Message: "is required"}
+	}
+	if !models.LooksLikeAdminToken(token) || len(token) < minBootstrapTokenLength {
+		return false, &ValidationError{Field: "token", Message: fmt.Sprintf("must start with adm_ and be at least %d characters", minBootstrapTokenLength)}
+	}
+
+	admin := &models.Admin{
+		Email:       email,
+		Name:        "Bootstrap super admin",
+		Roles:       []string{string(models.AdminRoleSuperAdmin)},
+		TokenHash:   s.hasher.Hash(token),
+		TokenPrefix: token[:models.APIKeyPrefixLength],
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}
+	created, err := s.repo.CreateFirstSuperAdmin(ctx, admin)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		log.Printf("Bootstrap super admin %d created for %s", admin.ID, email)
+	}
+	return created, nil
+}
+
+func (s *AdminService) List(ctx context.Context) ([]dto.AdminResponse, error) {
+	admins, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.AdminResponse, 0, len(admins))
+	for _, admin := range admins {
+		resp = append(resp, adminToResponse(admin))
+	}
+	return resp, nil
+}
+
+// UpdateRoles replaces the roles an admin holds
+func (s *AdminService) UpdateRoles(ctx context.Context, id int, req dto.AdminRolesUpdateRequest) error {
+	roles, err := parseAdminRoles(req.Roles)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateRoles(ctx, id, roles)
+}
+
+// Deactivate disables an admin's token
+func (s *AdminService) Deactivate(ctx context.Context, id int) error {
+	return s.repo.Deactivate(ctx, id)
+}
+
+func parseAdminRoles(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, &ValidationError{Field: "roles", Message: "must include at least one role"}
+	}
+	seen := make(map[string]bool, len(requested))
+	roles := make([]string, 0, len(requested))
+	for _, r := range requested {
+		role := strings.ToLower(strings.TrimSpace(r))
+		if !models.IsValidAdminRole(models.AdminRole(role)) {
+			return nil, &ValidationError{Field: "roles", Message: fmt.Sprintf("contains unknown role %q", r)}
+		}
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+func adminToResponse(admin *models.Admin) dto.AdminResponse {
+	return dto.AdminResponse{
+		ID:          admin.ID,
+		Email:       admin.Email,
+		Name:        admin.Name,
+		Roles:       admin.Roles,
+		TokenPrefix: admin.TokenPrefix,
+		IsActive:    admin.IsActive,
+		CreatedAt:   admin.CreatedAt.Format(time.RFC3339),
+	}
+}
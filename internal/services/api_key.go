@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyInactive = errors.New("api key is no longer active")
+	ErrAPIKeyExpired  = errors.New("api key has expired")
+	// ErrLiveKeyNotPermitted is returned when a live key is used by a merchant that cannot transact
+	ErrLiveKeyNotPermitted = errors.New("live api keys require approved kyc and an active account")
+	// ErrLiveKeysIssued is returned when a merchant claims live keys it already holds
+	ErrLiveKeysIssued = errors.New("live api keys have already been issued; rotate them to get new ones")
+	// ErrTestKeysIssued is returned when a merchant claims test keys it already holds
+	ErrTestKeysIssued = errors.New("test api keys have already been issued; rotate them to get new ones")
+)
+
+// IPNotAllowedError is returned when a key is used from outside its IP allowlist
+type IPNotAllowedError struct {
+	IP string
+}
+
+func (e *IPNotAllowedError) Error() string {
+	return fmt.Sprintf("ip address %s is not on this api key's allowlist", e.IP)
+}
+
+// SystemActor is recorded when the service itself revokes keys
+const SystemActor = "system"
+
+const (
+	maxAllowedIPEntries = 50
+	maxRevokeReasonLen  = 500
+
+	defaultUsageDays = 30
+	maxUsageDays     = 90
+
+	// defaultRotationGracePeriod keeps the previous key valid long enough for a normal deploy
+	defaultRotationGracePeriod = 24 * time.Hour
+	maxRotationGracePeriod     = 30 * 24 * time.Hour
+)
+
+// APIKeyService resolves, verifies and issues merchant API keys
+type APIKeyService struct {
+	repo         *repositories.APIKeyRepository
+	usageRepo    *repositories.APIKeyUsageRepository
+	merchantRepo *repositories.MerchantRepository
+	hasher       *models.APIKeyHasher
+}
+
+func NewAPIKeyService(repo *repositories.APIKeyRepository, usageRepo *repositories.APIKeyUsageRepository, merchantRepo *repositories.MerchantRepository, hasher *models.APIKeyHasher) *APIKeyService {
+	return &APIKeyService{repo: repo, usageRepo: usageRepo, merchantRepo: merchantRepo, hasher: hasher}
+}
+
+// Authenticate looks up a full pk_/sk_/rk_ key by its prefix, verifies it against the
+// stored hash and checks that clientIP is on the key's allowlist, if it has one
+func (s *APIKeyService) Authenticate(ctx context.Context, fullKey, clientIP string) (*models.APIKey, error) {
+	if !models.LooksLikeAPIKey(fullKey) || len(fullKey) <= models.APIKeyPrefixLength {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, fullKey[:models.APIKeyPrefixLength])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	// Verify the secret before revealing anything about the key's state
+	matched, needsRehash := s.hasher.Verify(fullKey, key.KeyHash)
+	if !matched {
+		return nil, ErrInvalidAPIKey
+	}
+	if !key.IsActive {
+		return nil, ErrAPIKeyInactive
+	}
+	if key.IsExpired(time.Now()) {
+		return nil, ErrAPIKeyExpired
+	}
+	if !key.AllowsIP(clientIP) {
+		return nil, &IPNotAllowedError{IP: clientIP}
+	}
+
+	// Live keys only work while the merchant is allowed to transact
+	if key.Environment == models.EnvironmentLive {
+		merchant, err := s.merchantRepo.GetByID(ctx, key.MerchantID)
+		if err != nil {
+			return nil, err
+		}
+		if !merchant.CanTransact() {
+			return nil, ErrLiveKeyNotPermitted
+		}
+	}
+
+	// Upgrade legacy or old-pepper hashes now that we hold the plaintext key
+	if needsRehash {
+		newHash := s.hasher.Hash(fullKey)
+		if err := s.repo.UpdateHash(ctx, key.ID, key.KeyHash, newHash); err != nil {
+			log.Printf("Failed to rehash API key %d: %v", key.ID, err)
+		} else {
+			key.KeyHash = newHash
+		}
+	}
+
+	return key, nil
+}
+
+// SyncLiveKeys brings a merchant's live keys in line with its current status.
+// Suspended merchants and merchants with rejected KYC lose their live keys. It reports
+// whether the merchant can now claim live keys through IssueLiveKeys; keys are never
+// issued here so their secrets only ever reach the merchant.
+func (s *APIKeyService) SyncLiveKeys(ctx context.Context, merchantID int) (bool, error) {
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return false, err
+	}
+
+	if merchant.Status == models.MerchantStatusSuspended || merchant.KYCStatus == models.KYCStatusRejected {
+		reason := fmt.Sprintf("merchant status %s, kyc status %s", merchant.Status, merchant.KYCStatus)
+		revoked, err := s.repo.DeactivateByMerchantAndEnvironment(ctx, merchantID, models.EnvironmentLive, SystemActor, reason)
+		if err != nil {
+			return false, fmt.Errorf("failed to revoke live keys: %w", err)
+		}
+		if revoked > 0 {
+			log.Printf("Revoked %d live API keys for merchant %d (status=%s, kyc_status=%s)", revoked, merchantID, merchant.Status, merchant.KYCStatus)
+		}
+		return false, nil
+	}
+
+	if !merchant.CanTransact() {
+		return false, nil
+	}
+	held, err := s.repo.ActiveKeyTypes(ctx, merchantID, models.EnvironmentLive)
+	if err != nil {
+		return false, err
+	}
+	return !hasKeyPair(held), nil
+}
+
+// keyPair are the key types every merchant holds in each environment
+var keyPair = []models.APIKeyType{models.APIKeyTypePublic, models.APIKeyTypeSecret}
+
+func hasKeyPair(held map[models.APIKeyType]bool) bool {
+	for _, keyType := range keyPair {
+		if !held[keyType] {
+			return false
+		}
+	}
+	return true
+}
+
+// IssueLiveKeys gives a merchant that can transact its pk_live_/sk_live_ pair, or the
+// half of the pair it is missing. The full key values are returned exactly once; once the
+// pair is complete later calls fail with ErrLiveKeysIssued and replacements go through Rotate.
+func (s *APIKeyService) IssueLiveKeys(ctx context.Context, merchantID int) ([]dto.APIKeyResponse, error) {
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !merchant.CanTransact() {
+		return nil, ErrLiveKeyNotPermitted
+	}
+
+	responses, err := s.issueKeyPair(ctx, merchantID, models.EnvironmentLive)
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) == 0 {
+		return nil, ErrLiveKeysIssued
+	}
+	log.Printf("Issued live API keys for merchant %d", merchantID)
+
+	return responses, nil
+}
+
+// IssueTestKeys gives a merchant its pk_test_/sk_test_ pair, or the half of the pair it
+// is missing, the same way IssueLiveKeys does for live keys. Once the pair is complete
+// later calls fail with ErrTestKeysIssued.
+func (s *APIKeyService) IssueTestKeys(ctx context.Context, merchantID int) ([]dto.APIKeyResponse, error) {
+	if _, err := s.merchantRepo.GetByID(ctx, merchantID); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.issueKeyPair(ctx, merchantID, models.EnvironmentTest)
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) == 0 {
+		return nil, ErrTestKeysIssued
+	}
+	log.Printf("Issued test API keys for merchant %d", merchantID)
+
+	return responses, nil
+}
+
+// issueKeyPair stores the keys of the pair a merchant is missing in env and returns them
+// with their full values. It returns no keys when the pair is already complete.
+func (s *APIKeyService) issueKeyPair(ctx context.Context, merchantID int, env models.Environment) ([]dto.APIKeyResponse, error) {
+	// Generate a full pair up front; only the types the merchant is missing are stored,
+	// atomically and under a lock on the merchant
+	candidates := make([]*models.APIKey, 0, len(keyPair))
+	fullKeys := make(map[*models.APIKey]string, len(keyPair))
+	for _, keyType := range keyPair {
+		key, fullKey, err := models.GenerateAPIKey(merchantID, keyType, env, s.hasher)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, key)
+		fullKeys[key] = fullKey
+	}
+	created, err := s.repo.CreateMissing(ctx, merchantID, env, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store %s keys: %w", env, err)
+	}
+
+	responses := make([]dto.APIKeyResponse, 0, len(created))
+	for _, key := range created {
+		responses = append(responses, apiKeyToResponse(key, fullKeys[key]))
+	}
+	return responses, nil
+}
+
+// List returns a merchant's usable keys without their full values. Keys are only
+// created through IssueTestKeys, IssueLiveKeys, Rotate and CreateRestricted.
+func (s *APIKeyService) List(ctx context.Context, merchantID int) []dto.APIKeyResponse {
+	keys, err := s.repo.ListByMerchantID(ctx, merchantID)
+	if err != nil {
+		return []dto.APIKeyResponse{}
+	}
+
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = apiKeyToResponse(key, "")
+	}
+	return responses
+}
+
+// Rotate issues a replacement key and keeps the keys it replaces valid until the
+// requested expiry, so integrations can switch over without downtime.
+func (s *APIKeyService) Rotate(ctx context.Context, merchantID int, req dto.APIKeyRotateRequest) (*dto.APIKeyRotateResponse, error) {
+	keyType := models.APIKeyType(strings.ToLower(strings.TrimSpace(req.Type)))
+	if keyType == "" {
+		keyType = models.APIKeyTypeSecret
+	}
+	if keyType != models.APIKeyTypePublic && keyType != models.APIKeyTypeSecret {
+		return nil, &ValidationError{Field: "type", Message: "must be public or secret"}
+	}
+
+	env := models.Environment(strings.ToLower(strings.TrimSpace(req.Environment)))
+	if env == "" {
+		env = models.EnvironmentTest
+	}
+	if env != models.EnvironmentTest && env != models.EnvironmentLive {
+		return nil, &ValidationError{Field: "environment", Message: "must be test or live"}
+	}
+
+	now := time.Now()
+	expiresAt, err := rotationExpiry(now, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if env == models.EnvironmentLive {
+		merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+		if err != nil {
+			return nil, err
+		}
+		if !merchant.CanTransact() {
+			return nil, ErrLiveKeyNotPermitted
+		}
+	}
+
+	// The replacement and the old keys' expiry are stored together, so a failure never
+	// leaves the merchant without a key or with old keys that never expire
+	newKey, fullKey, err := models.GenerateAPIKey(merchantID, keyType, env, s.hasher)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := s.repo.CreateReplacement(ctx, newKey, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate keys: %w", err)
+	}
+
+	return &dto.APIKeyRotateResponse{
+		APIKeyResponse:       apiKeyToResponse(newKey, fullKey),
+		PreviousKeysExpireAt: expiresAt.Format(time.RFC3339),
+		PreviousKeysAffected: affected,
+	}, nil
+}
+
+// CreateRestricted issues an rk_ key limited to the requested scopes
+func (s *APIKeyService) CreateRestricted(ctx context.Context, merchantID int, req dto.RestrictedAPIKeyCreateRequest) (*dto.APIKeyResponse, error) {
+	env := models.Environment(strings.ToLower(strings.TrimSpace(req.Environment)))
+	if env == "" {
+		env = models.EnvironmentTest
+	}
+	if env != models.EnvironmentTest && env != models.EnvironmentLive {
+		return nil, &ValidationError{Field: "environment", Message: "must be test or live"}
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, &ValidationError{Field: "scopes", Message: "must include at least one scope"}
+	}
+	seen := make(map[string]bool, len(req.Scopes))
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, raw := range req.Scopes {
+		scope := strings.ToLower(strings.TrimSpace(raw))
+		if !models.IsValidAPIKeyScope(models.APIKeyScope(scope)) {
+			return nil, &ValidationError{Field: "scopes", Message: fmt.Sprintf("contains unknown scope %q", raw)}
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if env == models.EnvironmentLive {
+		merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+		if err != nil {
+			return nil, err
+		}
+		if !merchant.CanTransact() {
+			return nil, ErrLiveKeyNotPermitted
+		}
+	}
+
+	key, fullKey, err := models.GenerateAPIKey(merchantID, models.APIKeyTypeRestricted, env, s.hasher)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = scopes
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store restricted key: %w", err)
+	}
+
+	resp := apiKeyToResponse(key, fullKey)
+	return &resp, nil
+}
+
+// Revoke deactivates exactly one key. actor identifies who asked for the revocation.
+func (s *APIKeyService) Revoke(ctx context.Context, merchantID, keyID int, actor string, req dto.APIKeyRevokeRequest) (*dto.APIKeyResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxRevokeReasonLen {
+		return nil, &ValidationError{Field: "reason", Message: fmt.Sprintf("must be at most %d characters", maxRevokeReasonLen)}
+	}
+	if err := s.repo.Revoke(ctx, merchantID, keyID, actor, reason); err != nil {
+		return nil, err
+	}
+	key, err := s.repo.GetByID(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("API key %d for merchant %d revoked by %s", keyID, merchantID, actor)
+	resp := apiKeyToResponse(key, "")
+	return &resp, nil
+}
+
+// ListRevoked returns a merchant's revoked keys
+func (s *APIKeyService) ListRevoked(ctx context.Context, merchantID int) ([]dto.APIKeyResponse, error) {
+	keys, err := s.repo.ListRevoked(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = apiKeyToResponse(key, "")
+	}
+	return responses, nil
+}
+
+// ListPendingExpiry returns keys that still work but are scheduled to expire
+func (s *APIKeyService) ListPendingExpiry(ctx context.Context, merchantID int) ([]dto.APIKeyResponse, error) {
+	keys, err := s.repo.ListPendingExpiry(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = apiKeyToResponse(key, "")
+	}
+	return responses, nil
+}
+
+// ExpireNow ends the grace period of a key that is pending expiry
+func (s *APIKeyService) ExpireNow(ctx context.Context, merchantID, keyID int) error {
+	return s.repo.ExpireNow(ctx, merchantID, keyID)
+}
+
+// GetAllowedIPs returns a key's IP allowlist
+func (s *APIKeyService) GetAllowedIPs(ctx context.Context, merchantID, keyID int) (*dto.APIKeyIPAllowlistResponse, error) {
+	key, err := s.repo.GetByID(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := key.AllowedIPs
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return &dto.APIKeyIPAllowlistResponse{KeyID: key.ID, AllowedIPs: allowed}, nil
+}
+
+// UpdateAllowedIPs replaces a key's IP allowlist. An empty list removes the restriction.
+func (s *APIKeyService) UpdateAllowedIPs(ctx context.Context, merchantID, keyID int, req dto.APIKeyIPAllowlistRequest) (*dto.APIKeyIPAllowlistResponse, error) {
+	key, err := s.repo.GetByID(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if !key.SupportsIPAllowlist() {
+		return nil, &ValidationError{Field: "allowed_ips", Message: "are not supported for publishable keys"}
+	}
+	if len(req.AllowedIPs) > maxAllowedIPEntries {
+		return nil, &ValidationError{Field: "allowed_ips", Message: fmt.Sprintf("can hold at most %d entries", maxAllowedIPEntries)}
+	}
+
+	cidrs := make([]string, 0, len(req.AllowedIPs))
+	seen := make(map[string]bool, len(req.AllowedIPs))
+	for _, entry := range req.AllowedIPs {
+		cidr, err := models.NormalizeCIDR(entry)
+		if err != nil {
+			return nil, &ValidationError{Field: "allowed_ips", Message: fmt.Sprintf("contains an invalid IP or CIDR range: %s", strings.TrimSpace(entry))}
+		}
+		if !seen[cidr] {
+			seen[cidr] = true
+			cidrs = append(cidrs, cidr)
+		}
+	}
+
+	if err := s.repo.UpdateAllowedIPs(ctx, merchantID, keyID, cidrs); err != nil {
+		return nil, err
+	}
+	return &dto.APIKeyIPAllowlistResponse{KeyID: keyID, AllowedIPs: cidrs}, nil
+}
+
+// Usage returns last-use details and daily request/error counts for one key
+func (s *APIKeyService) Usage(ctx context.Context, merchantID, keyID, days int) (*dto.APIKeyUsageResponse, error) {
+	if days <= 0 {
+		days = defaultUsageDays
+	}
+	if days > maxUsageDays {
+		days = maxUsageDays
+	}
+
+	key, err := s.repo.GetByID(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1))
+	daily, err := s.usageRepo.ListDaily(ctx, keyID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.APIKeyUsageResponse{
+		KeyID:      key.ID,
+		KeyPrefix:  key.KeyPrefix,
+		IsActive:   key.IsActive,
+		LastUsedAt: timePtrToString(key.LastUsedAt),
+		LastUsedIP: ptrToString(key.LastUsedIP),
+		Days:       days,
+		Daily:      make([]dto.APIKeyDailyUsage, 0, len(daily)),
+	}
+	for _, d := range daily {
+		resp.TotalRequests += d.RequestCount
+		resp.TotalErrors += d.ErrorCount
+		resp.Daily = append(resp.Daily, dto.APIKeyDailyUsage{
+			Date:     d.UsageDate.Format("2006-01-02"),
+			Requests: d.RequestCount,
+			Errors:   d.ErrorCount,
+		})
+	}
+	return resp, nil
+}
+
+// rotationExpiry works out when the previous keys stop working
+func rotationExpiry(now time.Time, req dto.APIKeyRotateRequest) (time.Time, error) {
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return time.Time{}, &ValidationError{Field: "expires_at", Message: "must be an RFC3339 timestamp"}
+		}
+		if expiresAt.Before(now) {
+			return time.Time{}, &ValidationError{Field: "expires_at", Message: "must be in the future"}
+		}
+		if expiresAt.Sub(now) > maxRotationGracePeriod {
+			return time.Time{}, &ValidationError{Field: "expires_at", Message: fmt.Sprintf("must be within %d days", int(maxRotationGracePeriod.Hours()/24))}
+		}
+		return expiresAt, nil
+	}
+
+	grace := defaultRotationGracePeriod
+	if req.GracePeriod != "" {
+		parsed, err := parseGracePeriod(req.GracePeriod)
+		if err != nil {
+			return time.Time{}, err
+		}
+		grace = parsed
+	}
+	if grace < 0 || grace > maxRotationGracePeriod {
+		return time.Time{}, &ValidationError{Field: "grace_period", Message: fmt.Sprintf("must be between 0 and %d days", int(maxRotationGracePeriod.Hours()/24))}
+	}
+	return now.Add(grace), nil
+}
+
+// parseGracePeriod accepts Go durations ("24h") and whole days ("7d")
+func parseGracePeriod(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, &ValidationError{Field: "grace_period", Message: "must look like 24h or 7d"}
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, &ValidationError{Field: "grace_period", Message: "must look like 24h or 7d"}
+	}
+	return d, nil
+}
+
+// apiKeyToResponse maps a stored key to its API representation; fullKey is only set on creation
+func apiKeyToResponse(key *models.APIKey, fullKey string) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		KeyID:        key.ID,
+		Key:          fullKey,
+		KeyPrefix:    key.KeyPrefix,
+		Type:         string(key.KeyType),
+		Environment:  string(key.Environment),
+		Scopes:       key.Scopes,
+		AllowedIPs:   key.AllowedIPs,
+		ExpiresAt:    timePtrToString(key.ExpiresAt),
+		RevokedAt:    timePtrToString(key.RevokedAt),
+		RevokedBy:    ptrToString(key.RevokedBy),
+		RevokeReason: ptrToString(key.RevokeReason),
+		CreatedAt:    key.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// RevokeAll revokes every active key a merchant holds, in both environments
+func (s *APIKeyService) RevokeAll(ctx context.Context, merchantID int, actor, reason string) (int64, error) {
+	var total int64
+	for _, env := range []models.Environment{models.EnvironmentTest, models.EnvironmentLive} {
+		revoked, err := s.repo.DeactivateByMerchantAndEnvironment(ctx, merchantID, env, actor, reason)
+		if err != nil {
+			return total, err
+		}
+		total += revoked
+	}
+	return total, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+// apiKeyUsage accumulates activity for one key between flushes
+type apiKeyUsage struct {
+	lastUsedAt time.Time
+	lastIP     string
+	daily      map[string]*dailyCounts // keyed by UTC date (YYYY-MM-DD)
+}
+
+type dailyCounts struct {
+	requests int64
+	errors   int64
+}
+
+// APIKeyUsageRecorder buffers API key usage in memory and writes it out in
+// batches, so authenticated requests do not each cost a database write.
+type APIKeyUsageRecorder struct {
+	apiKeyRepo *repositories.APIKeyRepository
+	usageRepo  *repositories.APIKeyUsageRepository
+	interval   time.Duration
+
+	mu      sync.Mutex
+	pending map[int]*apiKeyUsage
+}
+
+func NewAPIKeyUsageRecorder(apiKeyRepo *repositories.APIKeyRepository, usageRepo *repositories.APIKeyUsageRepository, interval time.Duration) *APIKeyUsageRecorder {
+	return &APIKeyUsageRecorder{
+		apiKeyRepo: apiKeyRepo,
+		usageRepo:  usageRepo,
+		interval:   interval,
+		pending:    make(map[int]*apiKeyUsage),
+	}
+}
+
+// Record notes one request made with a key
+func (r *APIKeyUsageRecorder) Record(keyID int, ip string, failed bool) {
+	now := time.Now().UTC()
+	day := now.Format("2006-01-02")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage, ok := r.pending[keyID]
+	if !ok {
+		usage = &apiKeyUsage{daily: make(map[string]*dailyCounts)}
+		r.pending[keyID] = usage
+	}
+	usage.lastUsedAt = now
+	usage.lastIP = ip
+
+	counts, ok := usage.daily[day]
+	if !ok {
+		counts = &dailyCounts{}
+		usage.daily[day] = counts
+	}
+	counts.requests++
+	if failed {
+		counts.errors++
+	}
+}
+
+// Run flushes buffered usage on every interval until ctx is cancelled
+func (r *APIKeyUsageRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.Flush(context.Background())
+			return
+		case <-ticker.C:
+			r.Flush(ctx)
+		}
+	}
+}
+
+// Flush writes all buffered usage to the database
+func (r *APIKeyUsageRecorder) Flush(ctx context.Context) {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[int]*apiKeyUsage)
+	r.mu.Unlock()
+
+	for keyID, usage := range batch {
+		if err := r.apiKeyRepo.TouchLastUsed(ctx, keyID, usage.lastUsedAt, usage.lastIP); err != nil {
+			log.Printf("Failed to record last use of API key %d: %v", keyID, err)
+		}
+		for day, counts := range usage.daily {
+			date, _ := time.Parse("2006-01-02", day)
+			if err := r.usageRepo.IncrementDaily(ctx, keyID, date, counts.requests, counts.errors); err != nil {
+				log.Printf("Failed to record daily usage of API key %d: %v", keyID, err)
+			}
+		}
+	}
+}
@@ -2,20 +2,22 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/kodra-pay/merchant-service/internal/dto"
 	"github.com/kodra-pay/merchant-service/internal/repositories"
 )
 
 type BalanceService struct {
-	repo *repositories.BalanceRepository
+	repo           *repositories.BalanceRepository
+	payoutAccounts *PayoutAccountService
 }
 
-func NewBalanceService(repo *repositories.BalanceRepository) *BalanceService {
-	return &BalanceService{repo: repo}
+func NewBalanceService(repo *repositories.BalanceRepository, payoutAccounts *PayoutAccountService) *BalanceService {
+	return &BalanceService{repo: repo, payoutAccounts: payoutAccounts}
 }
 
-// GetBalance returns the merchant's balance for a specific currency in currency units (e.g., NGN)
+// GetBalance returns the merchant's balance for a specific currency in currency units
 func (s *BalanceService) GetBalance(ctx context.Context, merchantID int, currency string) dto.MerchantBalanceResponse {
 	balance, err := s.repo.GetOrCreate(ctx, merchantID, currency)
 	if err != nil {
@@ -47,9 +49,22 @@ func (s *BalanceService) SettleFunds(ctx context.Context, merchantID int, curren
 	return s.repo.SettlePending(ctx, merchantID, currency, amount)
 }
 
-// ProcessPayout deducts amount from available balance
-func (s *BalanceService) ProcessPayout(ctx context.Context, merchantID int, currency string, amount int64) error {
-	return s.repo.DeductFromAvailable(ctx, merchantID, currency, amount)
+// ProcessPayout deducts amount from available balance and returns the account the funds
+// must be sent to. Nothing is deducted unless the merchant has a usable primary account.
+func (s *BalanceService) ProcessPayout(ctx context.Context, merchantID int, currency string, amount int64) (*dto.PayoutResponse, error) {
+	account, err := s.payoutAccounts.UsablePrimary(ctx, merchantID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeductFromAvailable(ctx, merchantID, currency, amount); err != nil {
+		return nil, err
+	}
+	return &dto.PayoutResponse{
+		MerchantID:    merchantID,
+		Currency:      currency,
+		Amount:        float64(amount) / 100,
+		PayoutAccount: payoutAccountToResponse(account, time.Now(), false),
+	}, nil
 }
 
 // Settle moves pending funds into available (used by settlement/payout flows)
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
+	"net/mail"
 	"strings"
 	"time"
 
@@ -12,14 +14,16 @@ import (
 )
 
 type KYCService struct {
-	merchantRepo *repositories.MerchantRepository
-	kycRepo      *repositories.KYCSubmissionRepository
+	merchantRepo  *repositories.MerchantRepository
+	kycRepo       *repositories.KYCSubmissionRepository
+	apiKeyService *APIKeyService
 }
 
-func NewKYCService(merchantRepo *repositories.MerchantRepository, kycRepo *repositories.KYCSubmissionRepository) *KYCService {
+func NewKYCService(merchantRepo *repositories.MerchantRepository, kycRepo *repositories.KYCSubmissionRepository, apiKeyService *APIKeyService) *KYCService {
 	return &KYCService{
-		merchantRepo: merchantRepo,
-		kycRepo:      kycRepo,
+		merchantRepo:  merchantRepo,
+		kycRepo:       kycRepo,
+		apiKeyService: apiKeyService,
 	}
 }
 
@@ -28,41 +32,60 @@ func (s *KYCService) Submit(ctx context.Context, req dto.KYCSubmissionRequest) (
 		return nil, fmt.Errorf("merchant_id is required")
 	}
 
+	merchant, err := s.merchantRepo.GetByID(ctx, req.MerchantID) // req.MerchantID is int
+	if err != nil {
+		return nil, fmt.Errorf("merchant not found")
+	}
+
 	businessType := strings.ToLower(strings.TrimSpace(req.BusinessType))
 	if businessType == "" {
-		businessType = "registered"
+		businessType = models.KYCBusinessRegistered
+	}
+	requirements, ok := models.KYCRequirementsFor(merchant.Country, businessType)
+	if !ok {
+		return nil, &ValidationError{Field: "business_type", Message: "must be one of " + strings.Join(models.KYCBusinessTypes, ", ")}
 	}
-	if businessType != "registered" && businessType != "startup" && businessType != "small_business" {
-		return nil, fmt.Errorf("business_type must be registered, startup, or small_business")
+	// Light-tier sub-merchants may also be individual sellers vouched for by their parent
+	if requirements.LightTierOnly && merchant.KYCTier != models.KYCTierLight {
+		return nil, &ValidationError{Field: "business_type", Message: "individual is only available to light-tier sub-merchants"}
 	}
 
-	merchant, err := s.merchantRepo.GetByID(ctx, req.MerchantID) // req.MerchantID is int
-	if err != nil {
-		return nil, fmt.Errorf("merchant not found")
+	values := kycSubmissionValues(req)
+	if err := validateKYCSubmission(requirements, values, req.Documents); err != nil {
+		return nil, err
 	}
 
 	submission := &models.KYCSubmission{
-		MerchantID:       merchant.ID, // merchant.ID is int
-		BusinessType:     businessType,
-		BusinessName:     req.BusinessName,
-		CACNumber:        req.CACNumber,
-		TINNumber:        req.TINNumber,
-		BusinessAddress:  req.BusinessAddress,
-		City:             req.City,
-		State:            req.State,
-		PostalCode:       req.PostalCode,
-		BusinessCategory: req.BusinessCategory,
-		DirectorName:     req.DirectorName,
-		DirectorBVN:      req.DirectorBVN,
-		DirectorPhone:    req.DirectorPhone,
-		DirectorEmail:    req.DirectorEmail,
-		Documents:        req.Documents,
-	}
-
-	if req.IncorporationDate != "" {
-		if parsed, err := time.Parse("2006-01-02", req.IncorporationDate); err == nil {
-			submission.IncorporationDate = &parsed
+		MerchantID:         merchant.ID, // merchant.ID is int
+		Country:            requirements.Country,
+		BusinessType:       businessType,
+		BusinessName:       values["business_name"],
+		RegistrationNumber: values["registration_number"],
+		TaxID:              values["tax_id"],
+		BusinessAddress:    values["business_address"],
+		City:               values["city"],
+		State:              values["state"],
+		PostalCode:         values["postal_code"],
+		BusinessCategory:   values["business_category"],
+		DirectorName:       values["director_name"],
+		DirectorIDNumber:   values["director_id_number"],
+		DirectorPhone:      values["director_phone"],
+		DirectorEmail:      values["director_email"],
+		Documents:          req.Documents,
+	}
+	// Nigerian consumers still read the CAC, TIN and BVN columns; other countries' numbers never go there
+	if requirements.Country == "NG" {
+		submission.CACNumber = submission.RegistrationNumber
+		submission.TINNumber = submission.TaxID
+		submission.DirectorBVN = submission.DirectorIDNumber
+	}
+
+	if date := values["incorporation_date"]; date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, &ValidationError{Field: "incorporation_date", Message: "must be a date in YYYY-MM-DD format"}
 		}
+		submission.IncorporationDate = &parsed
 	}
 
 	if err := s.kycRepo.Create(ctx, submission); err != nil {
@@ -79,6 +102,85 @@ func (s *KYCService) Submit(ctx context.Context, req dto.KYCSubmissionRequest) (
 	}, nil
 }
 
+// Requirements returns what a merchant of businessType in country must submit, defaulting
+// to registered businesses
+func (s *KYCService) Requirements(country, businessType string) (*models.KYCRequirements, error) {
+	code, ok := models.NormalizeCountryCode(country)
+	if !ok {
+		return nil, &ValidationError{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code"}
+	}
+	businessType = strings.ToLower(strings.TrimSpace(businessType))
+	if businessType == "" {
+		businessType = models.KYCBusinessRegistered
+	}
+	requirements, ok := models.KYCRequirementsFor(code, businessType)
+	if !ok {
+		return nil, &ValidationError{Field: "business_type", Message: "must be one of " + strings.Join(models.KYCBusinessTypes, ", ")}
+	}
+	return requirements, nil
+}
+
+// kycSubmissionValues collects a submission's fields under their requirement names,
+// trimmed and, for identifiers, upper-cased. Deprecated Nigerian field names fill in
+// for the country-neutral identifiers.
+func kycSubmissionValues(req dto.KYCSubmissionRequest) map[string]string {
+	identifier := func(value, legacy string) string {
+		if strings.TrimSpace(value) == "" {
+			value = legacy
+		}
+		return strings.ToUpper(strings.TrimSpace(value))
+	}
+	values := map[string]string{
+		"business_name":       req.BusinessName,
+		"business_category":   req.BusinessCategory,
+		"business_address":    req.BusinessAddress,
+		"city":                req.City,
+		"state":               req.State,
+		"incorporation_date":  req.IncorporationDate,
+		"director_name":       req.DirectorName,
+		"director_email":      req.DirectorEmail,
+		"director_phone":      req.DirectorPhone,
+		"registration_number": identifier(req.RegistrationNumber, req.CACNumber),
+		"tax_id":              identifier(req.TaxID, req.TINNumber),
+		"director_id_number":  identifier(req.DirectorIDNumber, req.DirectorBVN),
+		"postal_code":         strings.ToUpper(req.PostalCode),
+	}
+	for name, value := range values {
+		values[name] = strings.TrimSpace(value)
+	}
+	if phone, ok := models.NormalizePhoneNumber(values["director_phone"]); ok {
+		values["director_phone"] = phone
+	}
+	return values
+}
+
+// validateKYCSubmission checks values and documents against a requirements profile
+func validateKYCSubmission(requirements *models.KYCRequirements, values, documents map[string]string) error {
+	for _, field := range requirements.Fields {
+		value := values[field.Name]
+		if value == "" {
+			if field.Required {
+				return &ValidationError{Field: field.Name, Message: "is required"}
+			}
+			continue
+		}
+		if !field.Matches(value) {
+			return &ValidationError{Field: field.Name, Message: "does not match the expected format: " + field.Hint}
+		}
+	}
+	if email := values["director_email"]; email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return &ValidationError{Field: "director_email", Message: "must be a valid email address"}
+		}
+	}
+	for _, doc := range requirements.Documents {
+		if doc.Required && strings.TrimSpace(documents[doc.Type]) == "" {
+			return &ValidationError{Field: "documents." + doc.Type, Message: "is required"}
+		}
+	}
+	return nil
+}
+
 func (s *KYCService) GetLatest(ctx context.Context, merchantID int) (*dto.KYCStatusResponse, error) {
 	submission, err := s.kycRepo.GetLatestByMerchant(ctx, merchantID) // merchantID is int
 	if err != nil {
@@ -98,24 +200,34 @@ func (s *KYCService) GetLatest(ctx context.Context, merchantID int) (*dto.KYCSta
 	}, nil
 }
 
-func (s *KYCService) UpdateStatus(ctx context.Context, merchantID int, status string, reviewerID *int, notes *string) error {
+// UpdateStatus records a review decision. It reports whether the merchant can now
+// claim its live API keys; the keys themselves are only ever shown to the merchant.
+func (s *KYCService) UpdateStatus(ctx context.Context, merchantID int, status string, reviewerID *int, notes *string) (bool, error) {
 	status = strings.ToLower(status)
 	if status != "approved" && status != "rejected" && status != "pending" {
-		return fmt.Errorf("invalid status")
+		return false, fmt.Errorf("invalid status")
 	}
 
 	latest, err := s.kycRepo.GetLatestByMerchant(ctx, merchantID) // merchantID is int
 	if err != nil || latest == nil {
-		return fmt.Errorf("no kyc submission found for merchant")
+		return false, fmt.Errorf("no kyc submission found for merchant")
 	}
 
 	if err := s.kycRepo.UpdateStatus(ctx, latest.ID, status, reviewerID, notes); err != nil { // latest.ID is int, reviewerID is *int
-		return err
+		return false, err
 	}
 
 	// sync merchant KYC status
 	_ = s.merchantRepo.UpdateKYCStatus(ctx, merchantID, models.KYCStatus(status)) // merchantID is int
-	return nil
+
+	if s.apiKeyService == nil {
+		return false, nil
+	}
+	ready, err := s.apiKeyService.SyncLiveKeys(ctx, merchantID)
+	if err != nil {
+		log.Printf("Failed to sync live API keys for merchant %d: %v", merchantID, err)
+	}
+	return ready, nil
 }
 
 func (s *KYCService) ListByStatus(ctx context.Context, status string, limit int) ([]dto.KYCStatusResponse, error) {
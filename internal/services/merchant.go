@@ -2,11 +2,15 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
+	"net/mail"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kodra-pay/merchant-service/internal/clients"
 	"github.com/kodra-pay/merchant-service/internal/dto"
@@ -16,72 +20,226 @@ import (
 
 type MerchantService struct {
 	repo               *repositories.MerchantRepository
-	apiKeyRepo         *repositories.APIKeyRepository
+	apiKeyService      *APIKeyService
 	settlementRepo     *repositories.SettlementConfigRepository
+	balanceRepo        *repositories.BalanceRepository
+	paymentLinkRepo    *repositories.PaymentLinkRepository
+	currencyRepo       *repositories.MerchantCurrencyRepository
 	walletLedgerClient clients.WalletLedgerClient
 }
 
-func NewMerchantService(repo *repositories.MerchantRepository, apiKeyRepo *repositories.APIKeyRepository, settlementRepo *repositories.SettlementConfigRepository, walletLedgerClient clients.WalletLedgerClient) *MerchantService {
-	return &MerchantService{repo: repo, apiKeyRepo: apiKeyRepo, settlementRepo: settlementRepo, walletLedgerClient: walletLedgerClient}
+func NewMerchantService(repo *repositories.MerchantRepository, apiKeyService *APIKeyService, settlementRepo *repositories.SettlementConfigRepository, balanceRepo *repositories.BalanceRepository, paymentLinkRepo *repositories.PaymentLinkRepository, currencyRepo *repositories.MerchantCurrencyRepository, walletLedgerClient clients.WalletLedgerClient) *MerchantService {
+	return &MerchantService{
+		repo:               repo,
+		apiKeyService:      apiKeyService,
+		settlementRepo:     settlementRepo,
+		balanceRepo:        balanceRepo,
+		paymentLinkRepo:    paymentLinkRepo,
+		currencyRepo:       currencyRepo,
+		walletLedgerClient: walletLedgerClient,
+	}
 }
 
-func (s *MerchantService) List(ctx context.Context) []dto.MerchantResponse {
-	merchants, err := s.repo.List(ctx, "", 100, 0)
-	if err != nil {
-		log.Printf("ERROR: MerchantService.List - error from repository: %v", err)
-		return []dto.MerchantResponse{}
+const (
+	defaultMerchantPageSize = 50
+	maxMerchantPageSize     = 200
+)
+
+// Search returns one page of merchants matching the request's filters, newest first,
+// with the total number of matches and a cursor for the following page
+func (s *MerchantService) Search(ctx context.Context, req dto.MerchantSearchRequest) (*dto.MerchantListResponse, error) {
+	filter := repositories.MerchantFilter{Query: req.Query, ParentID: req.ParentID}
+	for _, status := range req.Statuses {
+		if !models.IsValidMerchantStatus(models.MerchantStatus(status)) {
+			return nil, &ValidationError{Field: "status", Message: fmt.Sprintf("contains unknown status %q", status)}
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	for _, kycStatus := range req.KYCStatuses {
+		if !models.IsValidKYCStatus(models.KYCStatus(kycStatus)) {
+			return nil, &ValidationError{Field: "kyc_status", Message: fmt.Sprintf("contains unknown status %q", kycStatus)}
+		}
+		filter.KYCStatuses = append(filter.KYCStatuses, kycStatus)
+	}
+	for _, country := range req.Countries {
+		code, ok := models.NormalizeCountryCode(country)
+		if !ok {
+			return nil, &ValidationError{Field: "country", Message: fmt.Sprintf("contains unknown country %q", country)}
+		}
+		filter.Countries = append(filter.Countries, code)
 	}
-	log.Printf("DEBUG: MerchantService.List - merchants retrieved: %d", len(merchants))
 
-	responses := make([]dto.MerchantResponse, len(merchants))
-	for i, m := range merchants {
-		responses[i] = dto.MerchantResponse{
-			ID:           m.ID,
-			Name:         m.Name,
-			Email:        m.Email,
-			BusinessName: m.BusinessName,
-			Status:       string(m.Status),
-			KYCStatus:    string(m.KYCStatus),
-			Country:      m.Country,
-			CanTransact:  m.CanTransact(),
+	var err error
+	if filter.CreatedFrom, err = parseDateBound("created_from", req.CreatedFrom, false); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = parseDateBound("created_to", req.CreatedTo, true); err != nil {
+		return nil, err
+	}
+
+	var after *repositories.MerchantCursor
+	if req.Cursor != "" {
+		if after, err = decodeMerchantCursor(req.Cursor); err != nil {
+			return nil, &ValidationError{Field: "cursor", Message: "is not valid"}
 		}
 	}
 
-	return responses
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultMerchantPageSize
+	}
+	if limit > maxMerchantPageSize {
+		limit = maxMerchantPageSize
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	merchants, err := s.repo.Search(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MerchantListResponse{Data: make([]dto.MerchantResponse, 0, limit), Total: total, Limit: limit}
+	if len(merchants) > limit {
+		merchants = merchants[:limit]
+		last := merchants[limit-1]
+		resp.NextCursor = encodeMerchantCursor(repositories.MerchantCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for _, m := range merchants {
+		resp.Data = append(resp.Data, merchantToResponse(m))
+	}
+	return resp, nil
+}
+
+// parseDateBound accepts RFC3339 timestamps or YYYY-MM-DD dates. A bare date used as an
+// exclusive upper bound is moved to the following day so the whole day is included.
+func parseDateBound(field, value string, upper bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, &ValidationError{Field: field, Message: "must be an RFC3339 timestamp or YYYY-MM-DD date"}
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// Cursors are opaque to clients: base64 of "<created_at unix nanos>:<id>"
+func encodeMerchantCursor(c repositories.MerchantCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+func decodeMerchantCursor(value string) (*repositories.MerchantCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	nanosPart, idPart, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	nanos, err := strconv.ParseInt(nanosPart, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return nil, err
+	}
+	return &repositories.MerchantCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// MerchantConflictError is returned when a merchant with the same unique field already
+// exists. It deliberately does not carry the existing merchant's ID.
+type MerchantConflictError struct {
+	Field string
 }
 
-func (s *MerchantService) Create(ctx context.Context, req dto.MerchantCreateRequest) dto.MerchantCreateResponse {
+func (e *MerchantConflictError) Error() string {
+	return fmt.Sprintf("a merchant with this %s already exists", e.Field)
+}
+
+// Create registers a merchant. Emails are normalized and must be unique.
+func (s *MerchantService) Create(ctx context.Context, req dto.MerchantCreateRequest) (dto.MerchantCreateResponse, error) {
+	merchant, err := s.create(ctx, req, nil, models.KYCTierStandard)
+	if err != nil {
+		return dto.MerchantCreateResponse{}, err
+	}
+	return dto.MerchantCreateResponse{ID: merchant.ID}, nil
+}
+
+func (s *MerchantService) create(ctx context.Context, req dto.MerchantCreateRequest, parentID *int, tier models.KYCTier) (*models.Merchant, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	// Signup accepts any country; known codes are normalized so currency and KYC lookups match
+	country := strings.TrimSpace(req.Country)
+	if code, ok := models.NormalizeCountryCode(country); ok {
+		country = code
+	}
+
+	// The merchants_email_lower_key index is authoritative: a concurrent signup that gets
+	// past this check fails on insert with ErrMerchantEmailTaken and gets the same conflict
+	if existing, err := s.repo.GetByEmail(ctx, email); err == nil && existing != nil {
+		return nil, &MerchantConflictError{Field: "email"}
+	} else if err != nil && !errors.Is(err, repositories.ErrMerchantNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
 	merchant := &models.Merchant{
-		Name:         req.Name,
-		Email:        req.Email,
-		BusinessName: req.BusinessName,
-		Country:      req.Country,
+		Name:         strings.TrimSpace(req.Name),
+		Email:        email,
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		Country:      country,
 		Status:       models.MerchantStatusInactive, // Set initial status
 		KYCStatus:    models.KYCStatusNotStarted,    // Set initial KYC status
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		KYCTier:      tier,
+		ParentID:     parentID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
-	err := s.repo.Create(ctx, merchant)
-	if err != nil {
-		// Log error in production
-		return dto.MerchantCreateResponse{ID: 0}
+	if err := s.repo.Create(ctx, merchant); err != nil {
+		if errors.Is(err, repositories.ErrMerchantEmailTaken) {
+			return nil, &MerchantConflictError{Field: "email"}
+		}
+		if errors.Is(err, repositories.ErrParentNotActive) {
+			return nil, ErrParentNotActive
+		}
+		return nil, err
 	}
 
-	// After creating the merchant, create a wallet for them
-	if err := s.ensureMerchantWallet(ctx, merchant.ID, "NGN"); err != nil {
-		log.Printf("Failed to provision wallet for merchant %d: %v", merchant.ID, err)
-		// Depending on business logic, you might want to handle this error differently
-	} else {
-		log.Printf("Successfully provisioned wallet for merchant %d", merchant.ID)
+	// Merchants start with their country's currency; admins can enable more later
+	currency := models.DefaultCurrencyForCountry(merchant.Country)
+	if _, err := s.currencyRepo.Enable(ctx, &models.MerchantCurrency{
+		MerchantID: merchant.ID,
+		Currency:   currency,
+		IsDefault:  true,
+		EnabledBy:  "system",
+		CreatedAt:  now,
+	}); err != nil {
+		log.Printf("Failed to enable %s for merchant %d: %v", currency, merchant.ID, err)
 	}
 
-	// Provision default settlement config (idempotent)
-	if err := s.ensureSettlementConfig(ctx, merchant.ID); err != nil {
-		log.Printf("Failed to provision default settlement config for merchant %d: %v", merchant.ID, err)
+	// After creating the merchant, create a wallet, settlement config and balance for them
+	if err := s.provisionCurrency(ctx, merchant.ID, currency).err(); err != nil {
+		log.Printf("Failed to provision %s for merchant %d: %v", currency, merchant.ID, err)
+	} else {
+		log.Printf("Successfully provisioned %s wallet for merchant %d", currency, merchant.ID)
 	}
 
-	return dto.MerchantCreateResponse{ID: merchant.ID}
+	return merchant, nil
 }
 
 func (s *MerchantService) Get(ctx context.Context, id int) dto.MerchantResponse {
@@ -89,17 +247,7 @@ func (s *MerchantService) Get(ctx context.Context, id int) dto.MerchantResponse
 	if err != nil {
 		return dto.MerchantResponse{}
 	}
-
-	return dto.MerchantResponse{
-		ID:           merchant.ID,
-		Name:         merchant.Name,
-		Email:        merchant.Email,
-		BusinessName: merchant.BusinessName,
-		Status:       string(merchant.Status),
-		KYCStatus:    string(merchant.KYCStatus),
-		Country:      merchant.Country,
-		CanTransact:  merchant.CanTransact(),
-	}
+	return merchantToResponse(merchant)
 }
 
 // GetAny returns the first merchant (fallback when id is not provided)
@@ -109,16 +257,7 @@ func (s *MerchantService) GetAny(ctx context.Context) dto.MerchantResponse {
 		return dto.MerchantResponse{}
 	}
 	m := merchants[0]
-	return dto.MerchantResponse{
-		ID:           m.ID,
-		Name:         m.Name,
-		Email:        m.Email,
-		BusinessName: m.BusinessName,
-		Status:       string(m.Status),
-		KYCStatus:    string(m.KYCStatus),
-		Country:      m.Country,
-		CanTransact:  m.CanTransact(),
-	}
+	return merchantToResponse(m)
 }
 
 // GetByEmail is a helper to retrieve by email if needed in other flows
@@ -127,16 +266,168 @@ func (s *MerchantService) GetByEmail(ctx context.Context, email string) dto.Merc
 	if err != nil || merchant == nil {
 		return dto.MerchantResponse{}
 	}
-	return dto.MerchantResponse{
-		ID:           merchant.ID,
-		Name:         merchant.Name,
-		Email:        merchant.Email,
-		BusinessName: merchant.BusinessName,
-		Status:       string(merchant.Status),
-		KYCStatus:    string(merchant.KYCStatus),
-		Country:      merchant.Country,
-		CanTransact:  merchant.CanTransact(),
+	return merchantToResponse(merchant)
+}
+
+// ValidationError reports an invalid field in a request
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+const (
+	minMerchantNameLen = 2
+	maxMerchantNameLen = 100
+	maxBusinessNameLen = 150
+	maxProfileURLLen   = 2048
+)
+
+// UpdateProfile applies a partial profile update. Changing a field that was verified
+// during KYC flags the merchant for re-review without changing its KYC status.
+func (s *MerchantService) UpdateProfile(ctx context.Context, id int, req dto.MerchantUpdateRequest) (dto.MerchantResponse, error) {
+	merchant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.MerchantResponse{}, err
+	}
+
+	kycFieldChanged := false
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateLength("name", name, minMerchantNameLen, maxMerchantNameLen); err != nil {
+			return dto.MerchantResponse{}, err
+		}
+		kycFieldChanged = kycFieldChanged || name != merchant.Name
+		merchant.Name = name
+	}
+	if req.Email != nil {
+		email, err := normalizeEmail(*req.Email)
+		if err != nil {
+			return dto.MerchantResponse{}, err
+		}
+		merchant.Email = email
+	}
+	if req.BusinessName != nil {
+		businessName := strings.TrimSpace(*req.BusinessName)
+		if err := validateLength("business_name", businessName, minMerchantNameLen, maxBusinessNameLen); err != nil {
+			return dto.MerchantResponse{}, err
+		}
+		kycFieldChanged = kycFieldChanged || businessName != merchant.BusinessName
+		merchant.BusinessName = businessName
 	}
+	if req.Country != nil {
+		country, ok := models.NormalizeCountryCode(*req.Country)
+		if !ok {
+			return dto.MerchantResponse{}, &ValidationError{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code"}
+		}
+		kycFieldChanged = kycFieldChanged || country != merchant.Country
+		merchant.Country = country
+	}
+
+	if err := applyBusinessProfile(merchant, req); err != nil {
+		return dto.MerchantResponse{}, err
+	}
+
+	// Only merchants whose KYC has been submitted have anything to re-review
+	if kycFieldChanged && merchant.KYCStatus != models.KYCStatusNotStarted {
+		merchant.KYCReviewRequired = true
+		log.Printf("Merchant %d changed KYC-relevant profile fields; flagged for re-review", id)
+	}
+
+	if err := s.repo.Update(ctx, merchant); err != nil {
+		if errors.Is(err, repositories.ErrMerchantEmailTaken) {
+			return dto.MerchantResponse{}, &MerchantConflictError{Field: "email"}
+		}
+		return dto.MerchantResponse{}, err
+	}
+	return merchantToResponse(merchant), nil
+}
+
+// applyBusinessProfile validates and normalizes the checkout profile fields present in req.
+// Empty strings clear a field.
+func applyBusinessProfile(merchant *models.Merchant, req dto.MerchantUpdateRequest) error {
+	if req.SupportEmail != nil {
+		merchant.SupportEmail = ""
+		if strings.TrimSpace(*req.SupportEmail) != "" {
+			email, err := normalizeEmail(*req.SupportEmail)
+			if err != nil {
+				return &ValidationError{Field: "support_email", Message: "must be a valid email address"}
+			}
+			merchant.SupportEmail = email
+		}
+	}
+	if req.SupportPhone != nil {
+		merchant.SupportPhone = ""
+		if strings.TrimSpace(*req.SupportPhone) != "" {
+			phone, ok := models.NormalizePhoneNumber(*req.SupportPhone)
+			if !ok {
+				return &ValidationError{Field: "support_phone", Message: "must be an international number in E.164 format, e.g. +2348012345678"}
+			}
+			merchant.SupportPhone = phone
+		}
+	}
+	if req.Website != nil {
+		merchant.Website = ""
+		if strings.TrimSpace(*req.Website) != "" {
+			website, ok := models.NormalizeWebURL(*req.Website, false)
+			if !ok || len(website) > maxProfileURLLen {
+				return &ValidationError{Field: "website", Message: "must be an absolute http or https URL"}
+			}
+			merchant.Website = website
+		}
+	}
+	if req.LogoURL != nil {
+		merchant.LogoURL = ""
+		if strings.TrimSpace(*req.LogoURL) != "" {
+			logoURL, ok := models.NormalizeWebURL(*req.LogoURL, true)
+			if !ok || len(logoURL) > maxProfileURLLen {
+				return &ValidationError{Field: "logo_url", Message: "must be an absolute https URL"}
+			}
+			merchant.LogoURL = logoURL
+		}
+	}
+	if req.BrandColor != nil {
+		merchant.BrandColor = ""
+		if strings.TrimSpace(*req.BrandColor) != "" {
+			color, ok := models.NormalizeBrandColor(*req.BrandColor)
+			if !ok {
+				return &ValidationError{Field: "brand_color", Message: "must be a hex colour such as #1A73E8"}
+			}
+			merchant.BrandColor = color
+		}
+	}
+	if req.StatementDescriptor != nil {
+		merchant.StatementDescriptor = ""
+		if strings.TrimSpace(*req.StatementDescriptor) != "" {
+			descriptor, ok := models.NormalizeStatementDescriptor(*req.StatementDescriptor)
+			if !ok {
+				return &ValidationError{Field: "statement_descriptor", Message: fmt.Sprintf(
+					"must be %d to %d Latin letters, digits or spaces, include a letter, and not contain < > \\ ' \" *",
+					models.MinStatementDescriptorLen, models.MaxStatementDescriptorLen)}
+			}
+			merchant.StatementDescriptor = descriptor
+		}
+	}
+	return nil
+}
+
+func validateLength(field, value string, min, max int) error {
+	if n := utf8.RuneCountInString(value); n < min || n > max {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("must be between %d and %d characters", min, max)}
+	}
+	return nil
+}
+
+func normalizeEmail(value string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "", &ValidationError{Field: "email", Message: "must be a valid email address"}
+	}
+	return email, nil
 }
 
 // GetMerchant returns the merchant model (for internal use)
@@ -152,16 +443,7 @@ func (s *MerchantService) ListByKYCStatus(ctx context.Context, kycStatus models.
 
 	responses := make([]dto.MerchantResponse, len(merchants))
 	for i, m := range merchants {
-		responses[i] = dto.MerchantResponse{
-			ID:           m.ID,
-			Name:         m.Name,
-			Email:        m.Email,
-			BusinessName: m.BusinessName,
-			Status:       string(m.Status),
-			KYCStatus:    string(m.KYCStatus),
-			Country:      m.Country,
-			CanTransact:  m.CanTransact(),
-		}
+		responses[i] = merchantToResponse(m)
 	}
 
 	return responses
@@ -182,106 +464,152 @@ func (s *MerchantService) UpdateKYCStatus(ctx context.Context, id int, req dto.M
 
 	resp := map[string]interface{}{"id": id, "kyc_status": string(kycStatus)}
 
-	// Provision a wallet for approved merchants (idempotent check against wallet-ledger service).
+	// Provision every enabled currency for approved merchants (idempotent checks against
+	// wallet-ledger service and our own tables)
 	if kycStatus == models.KYCStatusApproved {
-		if err := s.ensureMerchantWallet(ctx, id, "NGN"); err != nil {
-			log.Printf("Failed to provision wallet for merchant %d: %v", id, err)
-			resp["wallet_status"] = "error"
-			resp["wallet_error"] = err.Error()
-		} else {
-			resp["wallet_status"] = "created"
-		}
-
-		// Provision default settlement config (idempotent)
-		if err := s.ensureSettlementConfig(ctx, id); err != nil {
-			log.Printf("Failed to provision settlement config for merchant %d: %v", id, err)
-			resp["settlement_config"] = "error"
-			resp["settlement_error"] = err.Error()
-		} else {
-			resp["settlement_config"] = "created"
-		}
+		s.provisionEnabledCurrencies(ctx, id, resp)
 	}
 
+	s.syncLiveKeys(ctx, id, resp)
+
 	return resp
 }
 
-// ensureSettlementConfig creates a default settlement config if missing (idempotent via GetByMerchantID)
-func (s *MerchantService) ensureSettlementConfig(ctx context.Context, merchantID int) error {
+// syncLiveKeys revokes live API keys after a status change and tells the caller when
+// the merchant can now claim live keys from the dashboard
+func (s *MerchantService) syncLiveKeys(ctx context.Context, id int, resp map[string]interface{}) {
+	if s.apiKeyService == nil {
+		return
+	}
+	ready, err := s.apiKeyService.SyncLiveKeys(ctx, id)
+	if err != nil {
+		log.Printf("Failed to sync live API keys for merchant %d: %v", id, err)
+		resp["live_api_keys_error"] = "failed to sync live api keys"
+		return
+	}
+	if ready {
+		resp["live_api_keys_ready"] = true
+	}
+}
+
+// ensureSettlementConfig creates a default settlement config for currency if missing (idempotent via GetByMerchantID)
+func (s *MerchantService) ensureSettlementConfig(ctx context.Context, merchantID int, currency string) error {
 	if s.settlementRepo == nil {
 		return fmt.Errorf("settlement repository not configured")
 	}
-	_, err := s.settlementRepo.GetByMerchantID(ctx, merchantID)
+	_, err := s.settlementRepo.GetByMerchantID(ctx, merchantID, currency)
 	return err
 }
 
-func (s *MerchantService) UpdateStatus(ctx context.Context, id int, req dto.MerchantStatusUpdateRequest) map[string]interface{} {
-	status := models.MerchantStatus(req.Status)
-	err := s.repo.UpdateStatus(ctx, id, status)
-	if err != nil {
-		return map[string]interface{}{"id": id, "status": "error", "message": err.Error()}
+// StatusTransitionError is returned for a status change the transition table does not allow
+type StatusTransitionError struct {
+	From models.MerchantStatus
+	To   models.MerchantStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change merchant status from %s to %s", e.From, e.To)
+}
+
+const (
+	maxStatusNoteLen        = 1000
+	defaultStatusHistoryLen = 50
+	maxStatusHistoryLen     = 200
+)
+
+// UpdateStatus moves a merchant to a new status if the transition table allows it,
+// recording actor and reason in the status history. Closing goes through Close.
+func (s *MerchantService) UpdateStatus(ctx context.Context, id int, actor string, req dto.MerchantStatusUpdateRequest) (map[string]interface{}, error) {
+	status := models.MerchantStatus(strings.ToLower(strings.TrimSpace(req.Status)))
+	if !models.IsValidMerchantStatus(status) {
+		return nil, &ValidationError{Field: "status", Message: "is not a valid merchant status"}
+	}
+	if status == models.MerchantStatusClosed {
+		return nil, &ValidationError{Field: "status", Message: "cannot be set to closed; use POST /merchants/:id/close"}
 	}
-	return map[string]interface{}{"id": id, "status": req.Status}
+	return s.transitionStatus(ctx, id, actor, status, req)
 }
 
-func (s *MerchantService) ListAPIKeys(ctx context.Context, id int) []dto.APIKeyResponse {
-	keys, err := s.apiKeyRepo.ListByMerchantID(ctx, id)
+func (s *MerchantService) transitionStatus(ctx context.Context, id int, actor string, status models.MerchantStatus, req dto.MerchantStatusUpdateRequest) (map[string]interface{}, error) {
+	reason := models.StatusReasonCode(strings.ToLower(strings.TrimSpace(req.ReasonCode)))
+	if !isAllowedReason(status, reason) {
+		return nil, &ValidationError{Field: "reason_code", Message: fmt.Sprintf("must be one of %v", models.StatusReasonCodesFor(status))}
+	}
+	note := strings.TrimSpace(req.Note)
+	if reason == models.ReasonOther && note == "" {
+		return nil, &ValidationError{Field: "note", Message: "is required when reason_code is other"}
+	}
+	if len(note) > maxStatusNoteLen {
+		return nil, &ValidationError{Field: "note", Message: fmt.Sprintf("must be at most %d characters", maxStatusNoteLen)}
+	}
+
+	merchant, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return []dto.APIKeyResponse{}
+		return nil, err
+	}
+	if !merchant.Status.CanTransitionTo(status) {
+		return nil, &StatusTransitionError{From: merchant.Status, To: status}
 	}
 
-	// If no keys exist, create default test keys
-	if len(keys) == 0 {
-		// Create public test key
-		pubKey, pubFullKey, _ := models.GenerateAPIKey(id, models.APIKeyTypePublic, models.EnvironmentTest)
-		if pubKey != nil {
-			_ = s.apiKeyRepo.Create(ctx, pubKey)
-			keys = append(keys, pubKey)
-		}
+	entry := &models.MerchantStatusHistory{
+		MerchantID: id,
+		FromStatus: merchant.Status,
+		ToStatus:   status,
+		ReasonCode: reason,
+		Actor:      actor,
+		CreatedAt:  time.Now(),
+	}
+	if note != "" {
+		entry.Note = &note
+	}
+	if err := s.repo.TransitionStatus(ctx, entry); err != nil {
+		return nil, err
+	}
+	log.Printf("Merchant %d status %s -> %s by %s (reason=%s)", id, entry.FromStatus, status, actor, reason)
 
-		// Create secret test key
-		secKey, secFullKey, _ := models.GenerateAPIKey(id, models.APIKeyTypeSecret, models.EnvironmentTest)
-		if secKey != nil {
-			_ = s.apiKeyRepo.Create(ctx, secKey)
-			keys = append(keys, secKey)
-		}
+	resp := map[string]interface{}{"id": id, "status": string(status), "previous_status": string(entry.FromStatus), "reason_code": string(reason)}
+	s.syncLiveKeys(ctx, id, resp)
+	return resp, nil
+}
 
-		// Return with full keys on first creation
-		responses := make([]dto.APIKeyResponse, 0, len(keys))
-		if pubKey != nil {
-			responses = append(responses, dto.APIKeyResponse{
-				KeyID:       pubKey.ID,
-				Key:         pubFullKey,
-				KeyPrefix:   pubKey.KeyPrefix,
-				Type:        string(pubKey.KeyType),
-				Environment: string(pubKey.Environment),
-				CreatedAt:   pubKey.CreatedAt.Format(time.RFC3339),
-			})
-		}
-		if secKey != nil {
-			responses = append(responses, dto.APIKeyResponse{
-				KeyID:       secKey.ID,
-				Key:         secFullKey,
-				KeyPrefix:   secKey.KeyPrefix,
-				Type:        string(secKey.KeyType),
-				Environment: string(secKey.Environment),
-				CreatedAt:   secKey.CreatedAt.Format(time.RFC3339),
-			})
-		}
-		return responses
-	}
-
-	// Return existing keys without full key value
-	responses := make([]dto.APIKeyResponse, len(keys))
-	for i, key := range keys {
-		responses[i] = dto.APIKeyResponse{
-			KeyID:       key.ID,
-			KeyPrefix:   key.KeyPrefix,
-			Type:        string(key.KeyType),
-			Environment: string(key.Environment),
-			CreatedAt:   key.CreatedAt.Format(time.RFC3339),
+// StatusHistory returns a merchant's status changes, newest first
+func (s *MerchantService) StatusHistory(ctx context.Context, id, limit int) ([]dto.MerchantStatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultStatusHistoryLen
+	}
+	if limit > maxStatusHistoryLen {
+		limit = maxStatusHistoryLen
+	}
+
+	history, err := s.repo.ListStatusHistory(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.MerchantStatusHistoryResponse, 0, len(history))
+	for _, entry := range history {
+		resp = append(resp, dto.MerchantStatusHistoryResponse{
+			ID:         entry.ID,
+			FromStatus: string(entry.FromStatus),
+			ToStatus:   string(entry.ToStatus),
+			ReasonCode: string(entry.ReasonCode),
+			Note:       ptrToString(entry.Note),
+			Actor:      entry.Actor,
+			CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+func isAllowedReason(status models.MerchantStatus, reason models.StatusReasonCode) bool {
+	for _, allowed := range models.StatusReasonCodesFor(status) {
+		if allowed == reason {
+			return true
 		}
 	}
-	return responses
+	return false
 }
 
 // ListByKYCStatuses returns a list of merchants filtered by multiple KYC statuses
@@ -296,45 +624,12 @@ func (s *MerchantService) ListByKYCStatuses(ctx context.Context, kycStatuses []m
 
 	responses := make([]dto.MerchantResponse, len(merchants))
 	for i, m := range merchants {
-		responses[i] = dto.MerchantResponse{
-			ID:           m.ID,
-			Name:         m.Name,
-			Email:        m.Email,
-			BusinessName: m.BusinessName,
-			Status:       string(m.Status),
-			KYCStatus:    string(m.KYCStatus),
-			Country:      m.Country,
-			CanTransact:  m.CanTransact(),
-		}
+		responses[i] = merchantToResponse(m)
 	}
 
 	return responses
 }
 
-func (s *MerchantService) RotateAPIKey(ctx context.Context, id int) dto.APIKeyResponse {
-	// Deactivate old secret test key
-	_ = s.apiKeyRepo.DeactivateByMerchantAndType(ctx, id, models.APIKeyTypeSecret, models.EnvironmentTest)
-
-	// Generate new secret test key
-	newKey, fullKey, err := models.GenerateAPIKey(id, models.APIKeyTypeSecret, models.EnvironmentTest)
-	if err != nil {
-		return dto.APIKeyResponse{}
-	}
-
-	if err := s.apiKeyRepo.Create(ctx, newKey); err != nil {
-		return dto.APIKeyResponse{}
-	}
-
-	return dto.APIKeyResponse{
-		KeyID:       newKey.ID,
-		Key:         fullKey,
-		KeyPrefix:   newKey.KeyPrefix,
-		Type:        string(newKey.KeyType),
-		Environment: string(newKey.Environment),
-		CreatedAt:   newKey.CreatedAt.Format(time.RFC3339),
-	}
-}
-
 // ensureMerchantWallet checks for an existing wallet and creates one if missing.
 func (s *MerchantService) ensureMerchantWallet(ctx context.Context, merchantID int, currency string) error {
 	if s.walletLedgerClient == nil {
@@ -355,3 +650,26 @@ func (s *MerchantService) ensureMerchantWallet(ctx context.Context, merchantID i
 	})
 	return err
 }
+
+func merchantToResponse(m *models.Merchant) dto.MerchantResponse {
+	return dto.MerchantResponse{
+		ID:                m.ID,
+		Name:              m.Name,
+		Email:             m.Email,
+		BusinessName:      m.BusinessName,
+		Status:            string(m.Status),
+		KYCStatus:         string(m.KYCStatus),
+		KYCReviewRequired: m.KYCReviewRequired,
+		KYCTier:           string(m.KYCTier),
+		ParentID:          m.ParentID,
+		Country:           m.Country,
+		CanTransact:       m.CanTransact(),
+
+		SupportEmail:        m.SupportEmail,
+		SupportPhone:        m.SupportPhone,
+		Website:             m.Website,
+		LogoURL:             m.LogoURL,
+		BrandColor:          m.BrandColor,
+		StatementDescriptor: m.StatementDescriptor,
+	}
+}
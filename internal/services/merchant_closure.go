@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+// OutstandingBalanceError blocks closure while a merchant still holds funds
+type OutstandingBalanceError struct {
+	Balances []models.MerchantBalance
+}
+
+func (e *OutstandingBalanceError) Error() string {
+	currencies := make([]string, 0, len(e.Balances))
+	for _, b := range e.Balances {
+		currencies = append(currencies, b.Currency)
+	}
+	return fmt.Sprintf("merchant still holds pending or available funds in %s; settle or pay out before closing", strings.Join(currencies, ", "))
+}
+
+// Close offboards a merchant: it refuses while funds remain or while a marketplace
+// merchant has sub-merchants that are not closed, marks the merchant closed,
+// revokes every API key and deactivates payment links. Financial records are kept;
+// personal data is removed later by the retention job. Closing an already closed
+// merchant retries the key and link cleanup.
+func (s *MerchantService) Close(ctx context.Context, id int, actor string, req dto.MerchantCloseRequest) (*dto.MerchantCloseResponse, error) {
+	merchant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	closedAt := time.Now()
+	if merchant.Status == models.MerchantStatusClosed {
+		if merchant.ClosedAt != nil {
+			closedAt = *merchant.ClosedAt
+		}
+	} else {
+		_, err := s.transitionStatus(ctx, id, actor, models.MerchantStatusClosed, dto.MerchantStatusUpdateRequest{
+			ReasonCode: req.ReasonCode,
+			Note:       req.Note,
+		})
+		if errors.Is(err, repositories.ErrMerchantHasFunds) {
+			balances, listErr := s.balanceRepo.ListWithFunds(ctx, id)
+			if listErr != nil {
+				return nil, fmt.Errorf("failed to check balances: %w", listErr)
+			}
+			return nil, &OutstandingBalanceError{Balances: balances}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &dto.MerchantCloseResponse{ID: id, Status: string(models.MerchantStatusClosed), ClosedAt: closedAt.Format(time.RFC3339)}
+	if resp.APIKeysRevoked, err = s.apiKeyService.RevokeAll(ctx, id, actor, "merchant closed"); err != nil {
+		log.Printf("Failed to revoke API keys for closed merchant %d: %v", id, err)
+		resp.FailedSteps = append(resp.FailedSteps, "api_keys")
+	}
+	if resp.PaymentLinksDeactivated, err = s.paymentLinkRepo.DeactivateByMerchant(ctx, id); err != nil {
+		log.Printf("Failed to deactivate payment links for closed merchant %d: %v", id, err)
+		resp.FailedSteps = append(resp.FailedSteps, "payment_links")
+	}
+	resp.PartialFailure = len(resp.FailedSteps) > 0
+	log.Printf("Merchant %d closed by %s: %d API keys revoked, %d payment links deactivated", id, actor, resp.APIKeysRevoked, resp.PaymentLinksDeactivated)
+
+	return resp, nil
+}
+
+// MerchantRetentionJob anonymizes the personal data of merchants closed longer than the
+// retention period, including KYC submissions, payout bank details and team member
+// emails. Balances, payment links and status history are kept.
+type MerchantRetentionJob struct {
+	repo              *repositories.MerchantRepository
+	kycRepo           *repositories.KYCSubmissionRepository
+	payoutAccountRepo *repositories.PayoutAccountRepository
+	teamMemberRepo    *repositories.TeamMemberRepository
+	retention         time.Duration
+	interval          time.Duration
+}
+
+func NewMerchantRetentionJob(repo *repositories.MerchantRepository, kycRepo *repositories.KYCSubmissionRepository, payoutAccountRepo *repositories.PayoutAccountRepository, teamMemberRepo *repositories.TeamMemberRepository, retention, interval time.Duration) *MerchantRetentionJob {
+	return &MerchantRetentionJob{
+		repo:              repo,
+		kycRepo:           kycRepo,
+		payoutAccountRepo: payoutAccountRepo,
+		teamMemberRepo:    teamMemberRepo,
+		retention:         retention,
+		interval:          interval,
+	}
+}
+
+const retentionBatchSize = 100
+
+// Run anonymizes eligible merchants every interval until ctx is cancelled
+func (j *MerchantRetentionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if n, err := j.RunOnce(ctx); err != nil {
+			log.Printf("Merchant retention run failed after %d merchants: %v", n, err)
+		} else if n > 0 {
+			log.Printf("Merchant retention run anonymized %d merchants", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce anonymizes every merchant closed before the retention cutoff
+func (j *MerchantRetentionJob) RunOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-j.retention)
+	anonymized := 0
+	for {
+		merchants, err := j.repo.ListClosedBefore(ctx, cutoff, retentionBatchSize)
+		if err != nil {
+			return anonymized, err
+		}
+		for _, m := range merchants {
+			if err := j.kycRepo.AnonymizeByMerchant(ctx, m.ID); err != nil {
+				return anonymized, fmt.Errorf("merchant %d kyc submissions: %w", m.ID, err)
+			}
+			if err := j.payoutAccountRepo.AnonymizeByMerchant(ctx, m.ID); err != nil {
+				return anonymized, fmt.Errorf("merchant %d payout accounts: %w", m.ID, err)
+			}
+			if err := j.teamMemberRepo.AnonymizeByMerchant(ctx, m.ID); err != nil {
+				return anonymized, fmt.Errorf("merchant %d team members: %w", m.ID, err)
+			}
+			if err := j.repo.Anonymize(ctx, m.ID); err != nil {
+				return anonymized, fmt.Errorf("merchant %d: %w", m.ID, err)
+			}
+			anonymized++
+		}
+		if len(merchants) < retentionBatchSize {
+			return anonymized, nil
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+)
+
+// ErrCurrencyProvisioning is returned when an enabled currency's wallet, settlement config
+// or balance could not be set up. Enabling the currency again retries the setup.
+var ErrCurrencyProvisioning = errors.New("currency was enabled but could not be fully provisioned")
+
+// currencyProvisioning holds the outcome of each step of setting up a currency
+type currencyProvisioning struct {
+	wallet           error
+	settlementConfig error
+	balance          error
+}
+
+func (p currencyProvisioning) err() error {
+	var errs []error
+	if p.wallet != nil {
+		errs = append(errs, fmt.Errorf("wallet: %w", p.wallet))
+	}
+	if p.settlementConfig != nil {
+		errs = append(errs, fmt.Errorf("settlement config: %w", p.settlementConfig))
+	}
+	if p.balance != nil {
+		errs = append(errs, fmt.Errorf("balance: %w", p.balance))
+	}
+	return errors.Join(errs...)
+}
+
+// provisionCurrency gives a merchant a wallet, settlement config and balance in currency.
+// Every step is idempotent.
+func (s *MerchantService) provisionCurrency(ctx context.Context, merchantID int, currency string) currencyProvisioning {
+	var p currencyProvisioning
+	p.wallet = s.ensureMerchantWallet(ctx, merchantID, currency)
+	p.settlementConfig = s.ensureSettlementConfig(ctx, merchantID, currency)
+	_, p.balance = s.balanceRepo.GetOrCreate(ctx, merchantID, currency)
+	return p
+}
+
+// provisionEnabledCurrencies provisions each of the merchant's currencies and reports the
+// outcome on resp
+func (s *MerchantService) provisionEnabledCurrencies(ctx context.Context, merchantID int, resp map[string]interface{}) {
+	currencies, err := s.EnabledCurrencies(ctx, merchantID)
+	if err != nil {
+		log.Printf("Failed to load currencies for merchant %d: %v", merchantID, err)
+		resp["wallet_status"] = "error"
+		resp["wallet_error"] = err.Error()
+		return
+	}
+
+	var walletErrs, settlementErrs, balanceErrs []string
+	codes := make([]string, 0, len(currencies))
+	for _, mc := range currencies {
+		codes = append(codes, mc.Currency)
+		p := s.provisionCurrency(ctx, merchantID, mc.Currency)
+		if err := p.err(); err != nil {
+			log.Printf("Failed to provision %s for merchant %d: %v", mc.Currency, merchantID, err)
+		}
+		if p.wallet != nil {
+			walletErrs = append(walletErrs, mc.Currency+": "+p.wallet.Error())
+		}
+		if p.settlementConfig != nil {
+			settlementErrs = append(settlementErrs, mc.Currency+": "+p.settlementConfig.Error())
+		}
+		if p.balance != nil {
+			balanceErrs = append(balanceErrs, mc.Currency+": "+p.balance.Error())
+		}
+	}
+
+	resp["currencies"] = codes
+	resp["wallet_status"] = "created"
+	if len(walletErrs) > 0 {
+		resp["wallet_status"] = "error"
+		resp["wallet_error"] = strings.Join(walletErrs, "; ")
+	}
+	resp["settlement_config"] = "created"
+	if len(settlementErrs) > 0 {
+		resp["settlement_config"] = "error"
+		resp["settlement_error"] = strings.Join(settlementErrs, "; ")
+	}
+	if len(balanceErrs) > 0 {
+		resp["balance_error"] = strings.Join(balanceErrs, "; ")
+	}
+}
+
+// EnabledCurrencies returns the merchant's currencies, default first. Merchants onboarded
+// before currencies were tracked get their country's currency.
+func (s *MerchantService) EnabledCurrencies(ctx context.Context, merchantID int) ([]*models.MerchantCurrency, error) {
+	currencies, err := s.currencyRepo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(currencies) > 0 {
+		return currencies, nil
+	}
+	merchant, err := s.repo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.MerchantCurrency{{
+		MerchantID: merchant.ID,
+		Currency:   models.DefaultCurrencyForCountry(merchant.Country),
+		IsDefault:  true,
+		EnabledBy:  "system",
+		CreatedAt:  merchant.CreatedAt,
+	}}, nil
+}
+
+func (s *MerchantService) ListCurrencies(ctx context.Context, merchantID int) ([]dto.MerchantCurrencyResponse, error) {
+	currencies, err := s.EnabledCurrencies(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.MerchantCurrencyResponse, 0, len(currencies))
+	for _, mc := range currencies {
+		resp = append(resp, merchantCurrencyToResponse(mc))
+	}
+	return resp, nil
+}
+
+// EnableCurrency lets a merchant transact in another currency and provisions its wallet,
+// settlement config and balance. It reports whether the currency was newly enabled;
+// enabling a currency again only retries provisioning.
+func (s *MerchantService) EnableCurrency(ctx context.Context, merchantID int, actor string, req dto.MerchantCurrencyRequest) (*dto.MerchantCurrencyResponse, bool, error) {
+	currency, ok := models.NormalizeCurrencyCode(req.Currency)
+	if !ok {
+		return nil, false, &ValidationError{Field: "currency", Message: "is not a supported currency"}
+	}
+	merchant, err := s.repo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, false, err
+	}
+	if merchant.Status == models.MerchantStatusClosed {
+		return nil, false, &ValidationError{Field: "currency", Message: "cannot be enabled for a closed merchant"}
+	}
+
+	// Record the implicit default first so older merchants keep it once they have a row
+	existing, err := s.EnabledCurrencies(ctx, merchantID)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now()
+	for _, mc := range existing {
+		if mc.IsDefault && mc.Currency != currency {
+			if _, err := s.currencyRepo.Enable(ctx, mc); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	mc := &models.MerchantCurrency{
+		MerchantID: merchantID,
+		Currency:   currency,
+		IsDefault:  currency == models.DefaultCurrencyForCountry(merchant.Country),
+		EnabledBy:  actor,
+		CreatedAt:  now,
+	}
+	created, err := s.currencyRepo.Enable(ctx, mc)
+	if err != nil {
+		return nil, false, err
+	}
+	if !created {
+		for _, e := range existing {
+			if e.Currency == currency {
+				mc = e
+			}
+		}
+	} else {
+		log.Printf("Currency %s enabled for merchant %d by %s", currency, merchantID, actor)
+	}
+
+	resp := merchantCurrencyToResponse(mc)
+	if err := s.provisionCurrency(ctx, merchantID, currency).err(); err != nil {
+		log.Printf("Failed to provision %s for merchant %d: %v", currency, merchantID, err)
+		return &resp, created, fmt.Errorf("%w: %v", ErrCurrencyProvisioning, err)
+	}
+	return &resp, created, nil
+}
+
+// ResolveCurrency returns the currency a request applies to: requested if the merchant
+// has it enabled, or the merchant's default currency when requested is empty
+func (s *MerchantService) ResolveCurrency(ctx context.Context, merchantID int, requested string) (string, error) {
+	currencies, err := s.EnabledCurrencies(ctx, merchantID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(requested) == "" {
+		return currencies[0].Currency, nil
+	}
+	currency, ok := models.NormalizeCurrencyCode(requested)
+	if !ok {
+		return "", &ValidationError{Field: "currency", Message: "is not a supported currency"}
+	}
+	for _, mc := range currencies {
+		if mc.Currency == currency {
+			return currency, nil
+		}
+	}
+	return "", &ValidationError{Field: "currency", Message: fmt.Sprintf("%s is not enabled for this merchant", currency)}
+}
+
+func merchantCurrencyToResponse(mc *models.MerchantCurrency) dto.MerchantCurrencyResponse {
+	return dto.MerchantCurrencyResponse{
+		MerchantID: mc.MerchantID,
+		Currency:   mc.Currency,
+		IsDefault:  mc.IsDefault,
+		EnabledBy:  mc.EnabledBy,
+		CreatedAt:  mc.CreatedAt.Format(time.RFC3339),
+	}
+}
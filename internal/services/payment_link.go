@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/kodra-pay/merchant-service/internal/dto"
@@ -13,17 +14,21 @@ import (
 )
 
 type PaymentLinkService struct {
-	repo *repositories.PaymentLinkRepository
+	repo         *repositories.PaymentLinkRepository
+	merchantRepo *repositories.MerchantRepository
 }
 
-func NewPaymentLinkService(repo *repositories.PaymentLinkRepository) *PaymentLinkService {
-	return &PaymentLinkService{repo: repo}
+func NewPaymentLinkService(repo *repositories.PaymentLinkRepository, merchantRepo *repositories.MerchantRepository) *PaymentLinkService {
+	return &PaymentLinkService{repo: repo, merchantRepo: merchantRepo}
 }
 
 func (s *PaymentLinkService) DeletePaymentLink(ctx context.Context, id, merchantID int) error {
 	if id == 0 { // int check
 		return fmt.Errorf("id is required")
 	}
+	if merchantID == 0 {
+		return fmt.Errorf("merchant_id is required")
+	}
 	if err := s.repo.Delete(ctx, id, merchantID); err != nil {
 		if errors.Is(err, repositories.ErrPaymentLinkNotFound) || errors.Is(err, sql.ErrNoRows) {
 			return repositories.ErrPaymentLinkNotFound
@@ -59,6 +64,7 @@ func (s *PaymentLinkService) CreatePaymentLink(ctx context.Context, req dto.Crea
 		Description: link.Description,
 		Status:      link.Status,
 		URL:         url,
+		Checkout:    s.checkoutProfile(ctx, link.MerchantID),
 		CreatedAt:   link.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   link.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}, nil
@@ -84,6 +90,7 @@ func (s *PaymentLinkService) GetPaymentLink(ctx context.Context, id int) (*dto.P
 		Description: link.Description,
 		Status:      link.Status,
 		URL:         url,
+		Checkout:    s.checkoutProfile(ctx, link.MerchantID),
 		CreatedAt:   link.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   link.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}, nil
@@ -95,6 +102,7 @@ func (s *PaymentLinkService) ListPaymentLinks(ctx context.Context, merchantID in
 		return nil, err
 	}
 
+	checkout := s.checkoutProfile(ctx, merchantID)
 	responses := make([]dto.PaymentLinkResponse, 0, len(links))
 	for _, link := range links {
 		url := s.buildCheckoutURL(&link)
@@ -107,6 +115,7 @@ func (s *PaymentLinkService) ListPaymentLinks(ctx context.Context, merchantID in
 			Description: link.Description,
 			Status:      link.Status,
 			URL:         url,
+			Checkout:    checkout,
 			CreatedAt:   link.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   link.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		})
@@ -118,6 +127,25 @@ func (s *PaymentLinkService) ListPaymentLinks(ctx context.Context, merchantID in
 	}, nil
 }
 
+// checkoutProfile loads the branding and contact details the checkout page shows for a
+// merchant. Links still work without it, so a failed lookup is logged and skipped.
+func (s *PaymentLinkService) checkoutProfile(ctx context.Context, merchantID int) *dto.CheckoutProfile {
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		log.Printf("Failed to load checkout profile for merchant %d: %v", merchantID, err)
+		return nil
+	}
+	return &dto.CheckoutProfile{
+		BusinessName:        merchant.BusinessName,
+		LogoURL:             merchant.LogoURL,
+		BrandColor:          merchant.BrandColor,
+		SupportEmail:        merchant.SupportEmail,
+		SupportPhone:        merchant.SupportPhone,
+		Website:             merchant.Website,
+		StatementDescriptor: merchant.StatementDescriptor,
+	}
+}
+
 func (s *PaymentLinkService) buildCheckoutURL(link *models.PaymentLink) string {
 	// Build the checkout URL based on the payment link
 	baseURL := "http://localhost:5174/merchant/checkout"
@@ -55,12 +55,12 @@ func NewSettlementConfigService(repo *repositories.SettlementConfigRepository) *
 	return &SettlementConfigService{repo: repo}
 }
 
-// GetSettlementConfig retrieves settlement config for a merchant
-func (s *SettlementConfigService) GetSettlementConfig(ctx context.Context, merchantID int) (*models.SettlementConfig, error) {
-	return s.repo.GetByMerchantID(ctx, merchantID)
+// GetSettlementConfig retrieves a merchant's settlement config for a currency
+func (s *SettlementConfigService) GetSettlementConfig(ctx context.Context, merchantID int, currency string) (*models.SettlementConfig, error) {
+	return s.repo.GetByMerchantID(ctx, merchantID, currency)
 }
 
-// UpdateSettlementConfig updates settlement config for a merchant
+// UpdateSettlementConfig updates a merchant's settlement config for sc.Currency
 func (s *SettlementConfigService) UpdateSettlementConfig(ctx context.Context, merchantID int, sc *models.SettlementConfig) error {
 	// Validate merchant owns this configuration
 	if sc.MerchantID != merchantID {
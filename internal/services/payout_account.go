@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/clients"
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	// ErrBankResolverUnavailable is returned when account names cannot be verified right now
+	ErrBankResolverUnavailable = errors.New("bank account verification is temporarily unavailable")
+	// ErrNoUsablePayoutAccount blocks payouts until a primary account is out of its cooling-off period
+	ErrNoUsablePayoutAccount = errors.New("merchant has no usable payout account")
+	// ErrKYCReviewPending blocks account verification while profile changes await KYC review
+	ErrKYCReviewPending = errors.New("payout accounts cannot be verified until the merchant's changed profile has been re-reviewed for KYC")
+)
+
+var (
+	bankCodePattern      = regexp.MustCompile(`^[0-9A-Z]{3,11}$`)
+	accountNumberPattern = regexp.MustCompile(`^[0-9]{6,20}$`)
+	currencyCodePattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// PayoutAccountService manages the bank accounts merchants are paid out to. Every new or
+// changed account is verified with the bank and then cools off before it can receive money.
+type PayoutAccountService struct {
+	repo         *repositories.PayoutAccountRepository
+	merchantRepo *repositories.MerchantRepository
+	kycRepo      *repositories.KYCSubmissionRepository
+	resolver     clients.BankAccountResolver
+	coolingOff   time.Duration
+}
+
+func NewPayoutAccountService(repo *repositories.PayoutAccountRepository, merchantRepo *repositories.MerchantRepository, kycRepo *repositories.KYCSubmissionRepository, resolver clients.BankAccountResolver, coolingOff time.Duration) *PayoutAccountService {
+	return &PayoutAccountService{repo: repo, merchantRepo: merchantRepo, kycRepo: kycRepo, resolver: resolver, coolingOff: coolingOff}
+}
+
+func (s *PayoutAccountService) List(ctx context.Context, merchantID int) ([]dto.PayoutAccountResponse, error) {
+	accounts, err := s.repo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	resp := make([]dto.PayoutAccountResponse, 0, len(accounts))
+	for _, a := range accounts {
+		resp = append(resp, payoutAccountToResponse(a, now, true))
+	}
+	return resp, nil
+}
+
+// Create verifies and stores a new account. The first account in a currency becomes the
+// primary for that currency.
+func (s *PayoutAccountService) Create(ctx context.Context, merchantID int, actor string, req dto.PayoutAccountRequest) (*dto.PayoutAccountResponse, error) {
+	account := &models.PayoutAccount{MerchantID: merchantID, Status: models.PayoutAccountStatusActive}
+	if err := s.verify(ctx, account, req); err != nil {
+		return nil, err
+	}
+	account.CreatedAt = account.VerifiedAt
+	if err := s.repo.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	log.Printf("Payout account %d added for merchant %d by %s; usable from %s", account.ID, merchantID, actor, account.UsableFrom.Format(time.RFC3339))
+
+	resp := payoutAccountToResponse(account, time.Now(), true)
+	return &resp, nil
+}
+
+// Update replaces an account's bank details. The account is re-verified and cools off
+// again. Its currency is fixed, since the account may be that currency's primary.
+func (s *PayoutAccountService) Update(ctx context.Context, merchantID, id int, actor string, req dto.PayoutAccountRequest) (*dto.PayoutAccountResponse, error) {
+	account, err := s.repo.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if currency := strings.ToUpper(strings.TrimSpace(req.Currency)); currencyCodePattern.MatchString(currency) && currency != account.Currency {
+		return nil, &ValidationError{Field: "currency", Message: fmt.Sprintf("cannot be changed from %s; add a new account in %s instead", account.Currency, currency)}
+	}
+	if err := s.verify(ctx, account, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateDetails(ctx, account); err != nil {
+		return nil, err
+	}
+	log.Printf("Payout account %d changed for merchant %d by %s; usable from %s", id, merchantID, actor, account.UsableFrom.Format(time.RFC3339))
+
+	resp := payoutAccountToResponse(account, time.Now(), true)
+	return &resp, nil
+}
+
+// SetPrimary makes an account the one payouts in its currency are sent to
+func (s *PayoutAccountService) SetPrimary(ctx context.Context, merchantID, id int, actor string) (*dto.PayoutAccountResponse, error) {
+	if err := s.repo.SetPrimary(ctx, merchantID, id); err != nil {
+		return nil, err
+	}
+	account, err := s.repo.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Payout account %d set as primary for merchant %d by %s", id, merchantID, actor)
+
+	resp := payoutAccountToResponse(account, time.Now(), true)
+	return &resp, nil
+}
+
+func (s *PayoutAccountService) Remove(ctx context.Context, merchantID, id int, actor string) error {
+	if err := s.repo.Remove(ctx, merchantID, id); err != nil {
+		return err
+	}
+	log.Printf("Payout account %d removed for merchant %d by %s", id, merchantID, actor)
+	return nil
+}
+
+// UsablePrimary returns the primary account a payout in currency may be sent to
+func (s *PayoutAccountService) UsablePrimary(ctx context.Context, merchantID int, currency string) (*models.PayoutAccount, error) {
+	account, err := s.repo.GetPrimary(ctx, merchantID, currency)
+	if errors.Is(err, repositories.ErrPayoutAccountNotFound) {
+		return nil, fmt.Errorf("%w: no primary account is set for %s", ErrNoUsablePayoutAccount, currency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsUsable(time.Now()) {
+		return nil, fmt.Errorf("%w: the primary account is cooling off until %s", ErrNoUsablePayoutAccount, account.UsableFrom.Format(time.RFC3339))
+	}
+	return account, nil
+}
+
+// verify validates req, checks the account name with the bank and applies the details
+// to account, starting a new cooling-off period
+func (s *PayoutAccountService) verify(ctx context.Context, account *models.PayoutAccount, req dto.PayoutAccountRequest) error {
+	bankCode := strings.ToUpper(strings.TrimSpace(req.BankCode))
+	if !bankCodePattern.MatchString(bankCode) {
+		return &ValidationError{Field: "bank_code", Message: "must be 3 to 11 letters or digits"}
+	}
+	accountNumber := strings.ReplaceAll(strings.TrimSpace(req.AccountNumber), " ", "")
+	if !accountNumberPattern.MatchString(accountNumber) {
+		return &ValidationError{Field: "account_number", Message: "must be 6 to 20 digits"}
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !currencyCodePattern.MatchString(currency) {
+		return &ValidationError{Field: "currency", Message: "must be an ISO 4217 currency code"}
+	}
+	if strings.TrimSpace(req.AccountName) == "" {
+		return &ValidationError{Field: "account_name", Message: "is required"}
+	}
+
+	// Names are checked against the approved KYC, which is stale until changed profile
+	// fields have been re-reviewed
+	merchant, err := s.merchantRepo.GetByID(ctx, account.MerchantID)
+	if err != nil {
+		return err
+	}
+	if merchant.KYCReviewRequired {
+		return ErrKYCReviewPending
+	}
+
+	details, err := s.resolver.ResolveAccount(ctx, bankCode, accountNumber)
+	if errors.Is(err, clients.ErrBankAccountNotFound) {
+		return &ValidationError{Field: "account_number", Message: "was not found at this bank"}
+	}
+	if err != nil {
+		log.Printf("ERROR: bank account resolution failed for bank %s: %v", bankCode, err)
+		return ErrBankResolverUnavailable
+	}
+	if !models.AccountNamesMatch(req.AccountName, details.AccountName) {
+		return &ValidationError{Field: "account_name", Message: "does not match the name on the bank account"}
+	}
+	names, err := s.registeredNames(ctx, account.MerchantID)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return &ValidationError{Field: "account_name", Message: "cannot be checked until the merchant's KYC is approved"}
+	}
+	if !matchesAnyName(details.AccountName, names) {
+		return &ValidationError{Field: "account_name", Message: "does not match the business or director name in the merchant's approved KYC"}
+	}
+
+	now := time.Now()
+	account.BankCode = bankCode
+	account.AccountNumber = accountNumber
+	account.AccountName = details.AccountName
+	account.Currency = currency
+	account.VerifiedAt = now
+	account.UsableFrom = now.Add(s.coolingOff)
+	account.UpdatedAt = now
+	return nil
+}
+
+// registeredNames returns the names a merchant's payout accounts may be held in: the
+// business and director named in its latest approved KYC submission. Profile names are
+// not used because the merchant can change them freely.
+func (s *PayoutAccountService) registeredNames(ctx context.Context, merchantID int) ([]string, error) {
+	submission, err := s.kycRepo.GetLatestApprovedByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if submission == nil {
+		return nil, nil
+	}
+	return []string{submission.BusinessName, submission.DirectorName}, nil
+}
+
+func matchesAnyName(accountName string, names []string) bool {
+	for _, name := range names {
+		if models.AccountNamesMatch(name, accountName) {
+			return true
+		}
+	}
+	return false
+}
+
+// payoutAccountToResponse converts an account; masked hides the account number, which is
+// only revealed to internal payout callers
+func payoutAccountToResponse(a *models.PayoutAccount, now time.Time, masked bool) dto.PayoutAccountResponse {
+	accountNumber := a.AccountNumber
+	if masked {
+		accountNumber = a.MaskedAccountNumber()
+	}
+	return dto.PayoutAccountResponse{
+		ID:            a.ID,
+		MerchantID:    a.MerchantID,
+		BankCode:      a.BankCode,
+		AccountNumber: accountNumber,
+		AccountName:   a.AccountName,
+		Currency:      a.Currency,
+		IsPrimary:     a.IsPrimary,
+		Usable:        a.IsUsable(now),
+		UsableFrom:    a.UsableFrom.Format(time.RFC3339),
+		VerifiedAt:    a.VerifiedAt.Format(time.RFC3339),
+		CreatedAt:     a.CreatedAt.Format(time.RFC3339),
+	}
+}
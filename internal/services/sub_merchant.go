@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	ErrNestedSubMerchant = errors.New("sub-merchants cannot onboard sub-merchants of their own")
+	ErrParentNotActive   = errors.New("only active merchants can onboard sub-merchants")
+)
+
+// CreateSubMerchant onboards a seller under a marketplace merchant. The seller gets its
+// own wallet, settlement config and balances. Light KYC is only offered when the parent
+// has passed KYC itself.
+func (s *MerchantService) CreateSubMerchant(ctx context.Context, parentID int, req dto.SubMerchantCreateRequest) (dto.MerchantResponse, error) {
+	parent, err := s.repo.GetByID(ctx, parentID)
+	if err != nil {
+		return dto.MerchantResponse{}, err
+	}
+	if parent.IsSubMerchant() {
+		return dto.MerchantResponse{}, ErrNestedSubMerchant
+	}
+	if parent.Status != models.MerchantStatusActive {
+		return dto.MerchantResponse{}, ErrParentNotActive
+	}
+
+	tier := models.KYCTier(strings.ToLower(strings.TrimSpace(req.KYCTier)))
+	switch tier {
+	case "", models.KYCTierStandard:
+		tier = models.KYCTierStandard
+	case models.KYCTierLight:
+		if parent.KYCStatus != models.KYCStatusApproved {
+			return dto.MerchantResponse{}, &ValidationError{Field: "kyc_tier", Message: "light is only available once the parent merchant's KYC is approved"}
+		}
+	default:
+		return dto.MerchantResponse{}, &ValidationError{Field: "kyc_tier", Message: "must be standard or light"}
+	}
+
+	merchant, err := s.create(ctx, dto.MerchantCreateRequest{
+		Name:         req.Name,
+		Email:        req.Email,
+		BusinessName: req.BusinessName,
+		Country:      req.Country,
+	}, &parent.ID, tier)
+	if err != nil {
+		return dto.MerchantResponse{}, err
+	}
+	return merchantToResponse(merchant), nil
+}
+
+// ListSubMerchants pages through a parent's sub-merchants with the usual search filters
+func (s *MerchantService) ListSubMerchants(ctx context.Context, parentID int, req dto.MerchantSearchRequest) (*dto.MerchantListResponse, error) {
+	req.ParentID = &parentID
+	return s.Search(ctx, req)
+}
+
+// IsParentOf reports whether childID is a sub-merchant of parentID
+func (s *MerchantService) IsParentOf(ctx context.Context, parentID, childID int) (bool, error) {
+	child, err := s.repo.GetByID(ctx, childID)
+	if errors.Is(err, repositories.ErrMerchantNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return child.ParentID != nil && *child.ParentID == parentID, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	ErrNotTeamMember     = errors.New("not a member of this merchant's team")
+	ErrOwnerRoleRequired = errors.New("only owners can grant, change or remove the owner role")
+)
+
+// TeamService manages the people who may act on a merchant and is the source of
+// truth for dashboard sessions
+type TeamService struct {
+	repo          *repositories.TeamMemberRepository
+	hasher        *models.APIKeyHasher
+	invitationTTL time.Duration
+}
+
+func NewTeamService(repo *repositories.TeamMemberRepository, hasher *models.APIKeyHasher, invitationTTL time.Duration) *TeamService {
+	return &TeamService{repo: repo, hasher: hasher, invitationTTL: invitationTTL}
+}
+
+// ResolveSession returns the active membership of userID on a merchant. The first
+// user to sign in to a merchant without members becomes its owner.
+func (s *TeamService) ResolveSession(ctx context.Context, merchantID int, userID string) (*models.TeamMember, error) {
+	member, err := s.repo.GetActiveByUser(ctx, merchantID, userID)
+	if err == nil {
+		return member, nil
+	}
+	if !errors.Is(err, repositories.ErrTeamMemberNotFound) {
+		return nil, err
+	}
+
+	member, err = s.repo.BootstrapOwner(ctx, merchantID, userID)
+	if errors.Is(err, repositories.ErrTeamMemberNotFound) || errors.Is(err, repositories.ErrMerchantNotFound) {
+		return nil, ErrNotTeamMember
+	}
+	return member, err
+}
+
+func (s *TeamService) List(ctx context.Context, merchantID int) ([]dto.TeamMemberResponse, error) {
+	members, err := s.repo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.TeamMemberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, teamMemberToResponse(m))
+	}
+	return resp, nil
+}
+
+// Invite creates an invitation and returns its token, which is not retrievable again.
+// actor is the inviting member, or nil for admins and API keys.
+func (s *TeamService) Invite(ctx context.Context, merchantID int, actor *models.TeamMember, invitedBy string, req dto.TeamInviteRequest) (*dto.TeamMemberResponse, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	role, err := parseTeamRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.TeamRoleOwner && !isOwnerOrPlatform(actor) {
+		return nil, ErrOwnerRoleRequired
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.invitationTTL)
+	member := &models.TeamMember{
+		MerchantID:          merchantID,
+		Email:               email,
+		Role:                role,
+		Status:              models.TeamMemberStatusInvited,
+		InvitationExpiresAt: &expiresAt,
+		InvitedBy:           invitedBy,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	token, err := models.GenerateInvitationToken(member, s.hasher)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateInvitation(ctx, member); err != nil {
+		return nil, err
+	}
+
+	resp := teamMemberToResponse(member)
+	resp.InvitationToken = token
+	return &resp, nil
+}
+
+// AcceptInvitation binds a signed-in user to the invitation their token belongs to
+func (s *TeamService) AcceptInvitation(ctx context.Context, req dto.TeamInvitationAcceptRequest) (*dto.TeamMemberResponse, error) {
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		return nil, &ValidationError{Field: "user_id", Message: "is required"}
+	}
+	token := strings.TrimSpace(req.Token)
+	if !models.LooksLikeInvitationToken(token) || len(token) <= models.APIKeyPrefixLength {
+		return nil, repositories.ErrInvitationInvalid
+	}
+
+	member, err := s.repo.GetByInvitationPrefix(ctx, token[:models.APIKeyPrefixLength])
+	if err != nil {
+		return nil, err
+	}
+	if matched, _ := s.hasher.Verify(token, member.InvitationTokenHash); !matched {
+		return nil, repositories.ErrInvitationInvalid
+	}
+	// Invitations are addressed to a person, not to whoever holds the link
+	if !strings.EqualFold(strings.TrimSpace(req.Email), member.Email) {
+		return nil, &ValidationError{Field: "email", Message: "does not match the invitation"}
+	}
+
+	member, err = s.repo.AcceptInvitation(ctx, member.ID, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp := teamMemberToResponse(member)
+	return &resp, nil
+}
+
+// UpdateRole changes a member's role. Only owners may grant or take away the owner role.
+func (s *TeamService) UpdateRole(ctx context.Context, merchantID, id int, actor *models.TeamMember, req dto.TeamRoleUpdateRequest) (*dto.TeamMemberResponse, error) {
+	role, err := parseTeamRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if (role == models.TeamRoleOwner || target.Role == models.TeamRoleOwner) && !isOwnerOrPlatform(actor) {
+		return nil, ErrOwnerRoleRequired
+	}
+
+	member, err := s.repo.UpdateRole(ctx, merchantID, id, role)
+	if err != nil {
+		return nil, err
+	}
+	resp := teamMemberToResponse(member)
+	return &resp, nil
+}
+
+// Revoke removes a member or cancels a pending invitation. Only owners may remove owners.
+func (s *TeamService) Revoke(ctx context.Context, merchantID, id int, actor *models.TeamMember) (*dto.TeamMemberResponse, error) {
+	target, err := s.repo.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if target.Role == models.TeamRoleOwner && !isOwnerOrPlatform(actor) {
+		return nil, ErrOwnerRoleRequired
+	}
+
+	member, err := s.repo.Revoke(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := teamMemberToResponse(member)
+	return &resp, nil
+}
+
+// Memberships lists the merchants a user may act on, for the auth service
+func (s *TeamService) Memberships(ctx context.Context, userID string) ([]dto.TeamMemberResponse, error) {
+	members, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.TeamMemberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, teamMemberToResponse(m))
+	}
+	return resp, nil
+}
+
+// isOwnerOrPlatform reports whether actor may manage owners. A nil actor is an admin
+// or API key, which are not bound by team roles.
+func isOwnerOrPlatform(actor *models.TeamMember) bool {
+	return actor == nil || actor.Role == models.TeamRoleOwner
+}
+
+func parseTeamRole(value string) (models.TeamRole, error) {
+	role := models.TeamRole(strings.ToLower(strings.TrimSpace(value)))
+	if !models.IsValidTeamRole(role) {
+		return "", &ValidationError{Field: "role", Message: "must be one of owner, admin, developer, finance"}
+	}
+	return role, nil
+}
+
+func teamMemberToResponse(m *models.TeamMember) dto.TeamMemberResponse {
+	resp := dto.TeamMemberResponse{
+		ID:         m.ID,
+		MerchantID: m.MerchantID,
+		Email:      m.Email,
+		Role:       string(m.Role),
+		Status:     string(m.Status),
+		InvitedBy:  m.InvitedBy,
+		CreatedAt:  m.CreatedAt.Format(time.RFC3339),
+	}
+	if m.UserID != nil {
+		resp.UserID = *m.UserID
+	}
+	if m.Status == models.TeamMemberStatusInvited && m.InvitationExpiresAt != nil {
+		resp.InvitationExpiresAt = m.InvitationExpiresAt.Format(time.RFC3339)
+	}
+	if m.AcceptedAt != nil {
+		resp.AcceptedAt = m.AcceptedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/merchant-service/internal/auth"
+	"github.com/kodra-pay/merchant-service/internal/dto"
+	"github.com/kodra-pay/merchant-service/internal/models"
+	"github.com/kodra-pay/merchant-service/internal/replay"
+	"github.com/kodra-pay/merchant-service/internal/repositories"
+)
+
+var (
+	ErrTwoFactorUnavailable  = errors.New("two-factor authentication is not configured on this service")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrConfirmationRequired  = errors.New("this action requires two-factor confirmation")
+	ErrTwoFactorAlreadySetUp = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorLocked       = errors.New("too many two-factor attempts; try again later")
+)
+
+const (
+	totpIssuer        = "KodraPay"
+	recoveryCodeCount = 10
+)
+
+// TwoFactorService manages TOTP enrollment and issues the confirmation tokens
+// sensitive actions require once a merchant has two-factor authentication enabled
+type TwoFactorService struct {
+	repo         *repositories.TwoFactorRepository
+	merchantRepo *repositories.MerchantRepository
+	keys         *auth.StepUpKeys
+	hasher       *models.APIKeyHasher
+	replays      replay.Store // Confirmation tokens already used
+	maxAttempts  int
+	lockout      time.Duration
+}
+
+// NewTwoFactorService builds the service; keys may be nil when no two-factor secret is
+// configured, in which case enrollment is unavailable. After maxAttempts wrong codes in
+// a row, a merchant's codes are not checked until lockout has passed. replays records
+// used confirmation tokens so each unlocks a single request.
+func NewTwoFactorService(repo *repositories.TwoFactorRepository, merchantRepo *repositories.MerchantRepository, keys *auth.StepUpKeys, hasher *models.APIKeyHasher, replays replay.Store, maxAttempts int, lockout time.Duration) *TwoFactorService {
+	return &TwoFactorService{repo: repo, merchantRepo: merchantRepo, keys: keys, hasher: hasher, replays: replays, maxAttempts: maxAttempts, lockout: lockout}
+}
+
+func (s *TwoFactorService) Status(ctx context.Context, merchantID int) (*dto.TwoFactorStatusResponse, error) {
+	tf, err := s.repo.GetByMerchantID(ctx, merchantID)
+	if errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		return &dto.TwoFactorStatusResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !tf.Enabled {
+		return &dto.TwoFactorStatusResponse{}, nil
+	}
+	return &dto.TwoFactorStatusResponse{
+		Enabled:                true,
+		EnabledAt:              timePtrToString(tf.EnabledAt),
+		RecoveryCodesRemaining: len(tf.RecoveryCodeHashes),
+	}, nil
+}
+
+// Enroll starts enrollment with a fresh secret. It only takes effect once activated with a valid code.
+func (s *TwoFactorService) Enroll(ctx context.Context, merchantID int) (*dto.TwoFactorEnrollResponse, error) {
+	if s.keys == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.keys.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePending(ctx, merchantID, sealed); err != nil {
+		if errors.Is(err, repositories.ErrTwoFactorEnabled) {
+			return nil, ErrTwoFactorAlreadySetUp
+		}
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: auth.TOTPURL(totpIssuer, merchant.Email, secret),
+	}, nil
+}
+
+// Activate confirms enrollment with a code from the authenticator app and returns
+// single-use recovery codes, which are not retrievable again
+func (s *TwoFactorService) Activate(ctx context.Context, merchantID int, code string) (*dto.TwoFactorActivateResponse, error) {
+	tf, secret, err := s.load(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorAlreadySetUp
+	}
+	step, ok := auth.VerifyTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = s.hasher.Hash(codes[i])
+	}
+	if err := s.repo.Enable(ctx, merchantID, step, hashes); err != nil {
+		return nil, err
+	}
+	return &dto.TwoFactorActivateResponse{Enabled: true, RecoveryCodes: codes}, nil
+}
+
+// Confirm checks a TOTP or recovery code and issues a short-lived confirmation token
+// for one request of the requested action
+func (s *TwoFactorService) Confirm(ctx context.Context, merchantID int, req dto.TwoFactorConfirmRequest) (*dto.TwoFactorConfirmationResponse, error) {
+	action := models.ConfirmationAction(strings.TrimSpace(req.Action))
+	if !models.IsValidConfirmationAction(action) {
+		return nil, &ValidationError{Field: "action", Message: fmt.Sprintf("unknown action %q", req.Action)}
+	}
+	if err := s.checkCode(ctx, merchantID, req.TwoFactorCodeRequest); err != nil {
+		return nil, err
+	}
+	token, expiresAt, err := s.keys.IssueConfirmation(merchantID, string(action), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue confirmation token: %w", err)
+	}
+	return &dto.TwoFactorConfirmationResponse{
+		ConfirmationToken: token,
+		Action:            string(action),
+		ExpiresAt:         expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// Disable removes a merchant's enrollment after checking a TOTP or recovery code
+func (s *TwoFactorService) Disable(ctx context.Context, merchantID int, req dto.TwoFactorCodeRequest) error {
+	if err := s.checkCode(ctx, merchantID, req); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, merchantID)
+}
+
+// RequireConfirmation returns nil when merchantID has not enabled two-factor
+// authentication or token is a valid confirmation for it and action. The token is
+// used up: a second request with the same token fails with ErrConfirmationRequired.
+func (s *TwoFactorService) RequireConfirmation(ctx context.Context, merchantID int, action models.ConfirmationAction, token string) error {
+	tf, err := s.repo.GetByMerchantID(ctx, merchantID)
+	if errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled {
+		return nil
+	}
+	if s.keys == nil {
+		return ErrTwoFactorUnavailable
+	}
+	if token == "" {
+		return ErrConfirmationRequired
+	}
+	tokenID, expiresAt, err := s.keys.VerifyConfirmation(token, merchantID, string(action), time.Now())
+	if err != nil {
+		return ErrConfirmationRequired
+	}
+	// Remember the token a little past its expiry to cover clock skew between instances
+	first, err := s.replays.Claim(ctx, "confirmation:"+tokenID, time.Until(expiresAt)+time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to record confirmation token use: %w", err)
+	}
+	if !first {
+		return ErrConfirmationRequired
+	}
+	return nil
+}
+
+// checkCode verifies a TOTP or recovery code, counting the attempt against the merchant's limit
+func (s *TwoFactorService) checkCode(ctx context.Context, merchantID int, req dto.TwoFactorCodeRequest) error {
+	tf, secret, err := s.load(ctx, merchantID)
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled {
+		return repositories.ErrTwoFactorNotFound
+	}
+
+	allowed, err := s.repo.CountAttempt(ctx, merchantID, s.maxAttempts, s.lockout)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTwoFactorLocked
+	}
+	if err := s.verifyCode(ctx, merchantID, tf, secret, req); err != nil {
+		return err
+	}
+	return s.repo.ResetAttempts(ctx, merchantID)
+}
+
+func (s *TwoFactorService) verifyCode(ctx context.Context, merchantID int, tf *models.MerchantTwoFactor, secret string, req dto.TwoFactorCodeRequest) error {
+	if req.RecoveryCode != "" {
+		code := normalizeRecoveryCode(req.RecoveryCode)
+		for _, stored := range tf.RecoveryCodeHashes {
+			if ok, _ := s.hasher.Verify(code, stored); ok {
+				consumed, err := s.repo.ConsumeRecoveryCode(ctx, merchantID, stored)
+				if err != nil {
+					return err
+				}
+				if consumed {
+					return nil
+				}
+			}
+		}
+		return ErrInvalidTwoFactorCode
+	}
+
+	step, ok := auth.VerifyTOTP(secret, req.Code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	claimed, err := s.repo.ClaimStep(ctx, merchantID, step)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		// The code was already used
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *TwoFactorService) load(ctx context.Context, merchantID int) (*models.MerchantTwoFactor, string, error) {
+	if s.keys == nil {
+		return nil, "", ErrTwoFactorUnavailable
+	}
+	tf, err := s.repo.GetByMerchantID(ctx, merchantID)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := s.keys.Open(tf.EncryptedSecret)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt two-factor secret: %w", err)
+	}
+	return tf, secret, nil
+}
+
+// generateRecoveryCode returns a code such as "k3j9d-x82mq"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != 10 {
+		return code
+	}
+	return code[:5] + "-" + code[5:]
+}
@@ -0,0 +1,5 @@
+-- Migrations apply on top of the schema the service shipped with (merchants, api_keys,
+-- kyc_submissions, merchant_balances, settlement_configs, payment_options, payment_links).
+
+-- Restricted (rk_) keys carry the scopes they were granted; other keys hold none
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS api_key_usage_daily;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS last_used_ip;
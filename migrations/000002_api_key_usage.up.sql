@@ -0,0 +1,10 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS last_used_ip TEXT;
+
+-- Request and error counts per key and UTC day, flushed in batches by the usage recorder
+CREATE TABLE IF NOT EXISTS api_key_usage_daily (
+    api_key_id    INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    usage_date    DATE    NOT NULL,
+    request_count BIGINT  NOT NULL DEFAULT 0,
+    error_count   BIGINT  NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, usage_date)
+);
@@ -0,0 +1,2 @@
+-- Normalized CIDR ranges a secret or restricted key may be used from; empty means any address
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_ips TEXT[] NOT NULL DEFAULT '{}';
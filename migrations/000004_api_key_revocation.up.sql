@@ -0,0 +1,4 @@
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS revoked_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS revoked_by    TEXT,
+    ADD COLUMN IF NOT EXISTS revoke_reason TEXT;
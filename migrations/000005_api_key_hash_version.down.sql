@@ -0,0 +1,2 @@
+-- key_hash stays TEXT: peppered hashes no longer fit the original width
+SELECT 1;
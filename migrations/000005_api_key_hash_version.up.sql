@@ -0,0 +1,3 @@
+-- Peppered hashes are stored as hmac-sha256$<pepper version>$<hex digest>, longer than the
+-- bare SHA-256 hex digests they replace. Legacy hashes are upgraded on their next use.
+ALTER TABLE api_keys ALTER COLUMN key_hash TYPE TEXT;
@@ -0,0 +1,12 @@
+-- Back-office admins authenticate with adm_ tokens, looked up by prefix and verified
+-- against token_hash
+CREATE TABLE IF NOT EXISTS admin_users (
+    id           SERIAL PRIMARY KEY,
+    email        TEXT        NOT NULL UNIQUE,
+    name         TEXT        NOT NULL DEFAULT '',
+    roles        TEXT[]      NOT NULL DEFAULT '{}',
+    token_hash   TEXT        NOT NULL,
+    token_prefix TEXT        NOT NULL UNIQUE,
+    is_active    BOOLEAN     NOT NULL DEFAULT true,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS merchant_two_factor;
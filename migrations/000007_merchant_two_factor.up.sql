@@ -0,0 +1,13 @@
+-- One TOTP enrollment per merchant. The secret is encrypted with a key derived from
+-- TWO_FACTOR_SECRET and recovery codes are stored hashed.
+CREATE TABLE IF NOT EXISTS merchant_two_factor (
+    merchant_id     INTEGER     PRIMARY KEY REFERENCES merchants (id) ON DELETE CASCADE,
+    secret          TEXT        NOT NULL,
+    enabled         BOOLEAN     NOT NULL DEFAULT false,
+    recovery_codes  TEXT[]      NOT NULL DEFAULT '{}',
+    last_used_step  BIGINT      NOT NULL DEFAULT 0,
+    failed_attempts INTEGER     NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    enabled_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
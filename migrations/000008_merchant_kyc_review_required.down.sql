@@ -0,0 +1 @@
+ALTER TABLE merchants DROP COLUMN IF EXISTS kyc_review_required;
@@ -0,0 +1,2 @@
+-- Set when a KYC-relevant profile field changes after submission; cleared by the next KYC decision
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS kyc_review_required BOOLEAN NOT NULL DEFAULT false;
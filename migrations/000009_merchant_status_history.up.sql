@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS merchant_status_history (
+    id          SERIAL PRIMARY KEY,
+    merchant_id INTEGER     NOT NULL REFERENCES merchants (id) ON DELETE CASCADE,
+    from_status TEXT        NOT NULL,
+    to_status   TEXT        NOT NULL,
+    reason_code TEXT        NOT NULL,
+    note        TEXT,
+    actor       TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS merchant_status_history_merchant_idx
+    ON merchant_status_history (merchant_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS merchants_email_lower_key;
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,15 @@
+-- Responses to POST /merchants retried with the same Idempotency-Key. response_status is
+-- NULL while the original request is still being processed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope           TEXT        NOT NULL,
+    key             TEXT        NOT NULL,
+    request_hash    TEXT        NOT NULL,
+    response_status INTEGER,
+    response_body   BYTEA,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, key)
+);
+
+-- Signup relies on this index to turn concurrent duplicate emails into a 409. Merchants
+-- whose emails differ only in case must be merged before it can be built.
+CREATE UNIQUE INDEX IF NOT EXISTS merchants_email_lower_key ON merchants (lower(email));
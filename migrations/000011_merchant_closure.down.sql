@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS merchants_pending_anonymization_idx;
+ALTER TABLE merchants
+    DROP COLUMN IF EXISTS anonymized_at,
+    DROP COLUMN IF EXISTS closed_at;
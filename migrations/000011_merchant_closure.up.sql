@@ -0,0 +1,7 @@
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS closed_at     TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+-- Closed merchants still waiting for the retention job
+CREATE INDEX IF NOT EXISTS merchants_pending_anonymization_idx
+    ON merchants (closed_at) WHERE status = 'closed' AND anonymized_at IS NULL;
@@ -0,0 +1,26 @@
+-- People who may act on a merchant. Invitations are rows in the invited status whose
+-- token hash is cleared once accepted.
+CREATE TABLE IF NOT EXISTS merchant_team_members (
+    id                      SERIAL PRIMARY KEY,
+    merchant_id             INTEGER     NOT NULL REFERENCES merchants (id) ON DELETE CASCADE,
+    email                   TEXT        NOT NULL,
+    user_id                 TEXT,
+    role                    TEXT        NOT NULL,
+    status                  TEXT        NOT NULL,
+    invitation_token_hash   TEXT,
+    invitation_token_prefix TEXT,
+    invitation_expires_at   TIMESTAMPTZ,
+    invited_by              TEXT,
+    accepted_at             TIMESTAMPTZ,
+    revoked_at              TIMESTAMPTZ,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A person is on a team, or invited to it, at most once
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_team_members_email_key
+    ON merchant_team_members (merchant_id, lower(email)) WHERE status IN ('invited', 'active');
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_team_members_user_key
+    ON merchant_team_members (merchant_id, user_id) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_team_members_invitation_key
+    ON merchant_team_members (invitation_token_prefix) WHERE invitation_token_prefix IS NOT NULL;
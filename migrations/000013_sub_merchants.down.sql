@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS merchants_parent_id_idx;
+ALTER TABLE merchants
+    DROP COLUMN IF EXISTS kyc_tier,
+    DROP COLUMN IF EXISTS parent_id;
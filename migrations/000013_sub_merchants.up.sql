@@ -0,0 +1,7 @@
+-- Marketplace sub-merchants point at the platform merchant that onboarded them and may
+-- complete the light KYC tier
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES merchants (id),
+    ADD COLUMN IF NOT EXISTS kyc_tier  TEXT NOT NULL DEFAULT 'standard';
+
+CREATE INDEX IF NOT EXISTS merchants_parent_id_idx ON merchants (parent_id) WHERE parent_id IS NOT NULL;
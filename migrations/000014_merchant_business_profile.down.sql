@@ -0,0 +1,7 @@
+ALTER TABLE merchants
+    DROP COLUMN IF EXISTS statement_descriptor,
+    DROP COLUMN IF EXISTS brand_color,
+    DROP COLUMN IF EXISTS logo_url,
+    DROP COLUMN IF EXISTS website,
+    DROP COLUMN IF EXISTS support_phone,
+    DROP COLUMN IF EXISTS support_email;
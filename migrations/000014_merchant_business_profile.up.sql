@@ -0,0 +1,8 @@
+-- Checkout profile fields; empty when the merchant has not set them
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS support_email        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS support_phone        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS website              TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS logo_url             TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS brand_color          TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS statement_descriptor TEXT NOT NULL DEFAULT '';
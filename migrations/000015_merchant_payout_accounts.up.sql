@@ -0,0 +1,21 @@
+-- Bank accounts merchants are paid out to. Removed and anonymized accounts keep their rows
+-- so past payouts still resolve.
+CREATE TABLE IF NOT EXISTS merchant_payout_accounts (
+    id             SERIAL PRIMARY KEY,
+    merchant_id    INTEGER     NOT NULL REFERENCES merchants (id),
+    bank_code      TEXT        NOT NULL,
+    account_number TEXT        NOT NULL,
+    account_name   TEXT        NOT NULL,
+    currency       TEXT        NOT NULL,
+    is_primary     BOOLEAN     NOT NULL DEFAULT false,
+    status         TEXT        NOT NULL DEFAULT 'active',
+    verified_at    TIMESTAMPTZ NOT NULL,
+    usable_from    TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_payout_accounts_account_key
+    ON merchant_payout_accounts (merchant_id, bank_code, account_number) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_payout_accounts_primary_key
+    ON merchant_payout_accounts (merchant_id) WHERE status = 'active' AND is_primary;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS settlement_configs_merchant_currency_key;
+DROP TABLE IF EXISTS merchant_currencies;
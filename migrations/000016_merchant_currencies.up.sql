@@ -0,0 +1,15 @@
+-- Currencies a merchant may transact and settle in. Merchants without rows use their
+-- country's currency until one is enabled.
+CREATE TABLE IF NOT EXISTS merchant_currencies (
+    merchant_id INTEGER     NOT NULL REFERENCES merchants (id) ON DELETE CASCADE,
+    currency    TEXT        NOT NULL,
+    is_default  BOOLEAN     NOT NULL DEFAULT false,
+    enabled_by  TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (merchant_id, currency)
+);
+
+-- Settlement configs are now kept per currency
+ALTER TABLE settlement_configs DROP CONSTRAINT IF EXISTS settlement_configs_merchant_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS settlement_configs_merchant_currency_key
+    ON settlement_configs (merchant_id, currency);
@@ -0,0 +1,5 @@
+ALTER TABLE kyc_submissions
+    DROP COLUMN IF EXISTS director_id_number,
+    DROP COLUMN IF EXISTS tax_id,
+    DROP COLUMN IF EXISTS registration_number,
+    DROP COLUMN IF EXISTS country;
@@ -0,0 +1,14 @@
+-- Identifiers in the formats of the submission's country. cac_number, tin_number and
+-- director_bvn are only filled for Nigerian merchants.
+ALTER TABLE kyc_submissions
+    ADD COLUMN IF NOT EXISTS country             TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS registration_number TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tax_id              TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS director_id_number  TEXT NOT NULL DEFAULT '';
+
+-- Earlier submissions kept every country's identifiers in the Nigerian columns
+UPDATE kyc_submissions k
+SET country = upper(m.country), registration_number = COALESCE(k.cac_number, ''),
+    tax_id = COALESCE(k.tin_number, ''), director_id_number = COALESCE(k.director_bvn, '')
+FROM merchants m
+WHERE m.id = k.merchant_id AND k.country = '';
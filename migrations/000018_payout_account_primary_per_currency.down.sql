@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS merchant_payout_accounts_currency_primary_key;
+
+-- Keep only each merchant's oldest primary account
+UPDATE merchant_payout_accounts
+SET is_primary = false, updated_at = NOW()
+WHERE status = 'active' AND is_primary
+  AND id NOT IN (
+    SELECT DISTINCT ON (merchant_id) id
+    FROM merchant_payout_accounts
+    WHERE status = 'active' AND is_primary
+    ORDER BY merchant_id, created_at, id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_payout_accounts_primary_key
+    ON merchant_payout_accounts (merchant_id) WHERE status = 'active' AND is_primary;
@@ -0,0 +1,20 @@
+-- Merchants keep one primary payout account per currency instead of one overall
+DROP INDEX IF EXISTS merchant_payout_accounts_primary_key;
+
+-- The oldest active account becomes primary in each currency that has none
+UPDATE merchant_payout_accounts
+SET is_primary = true, updated_at = NOW()
+WHERE id IN (
+    SELECT DISTINCT ON (o.merchant_id, o.currency) o.id
+    FROM merchant_payout_accounts o
+    WHERE o.status = 'active'
+      AND NOT EXISTS (
+        SELECT 1 FROM merchant_payout_accounts p
+        WHERE p.merchant_id = o.merchant_id AND p.currency = o.currency
+          AND p.status = 'active' AND p.is_primary
+      )
+    ORDER BY o.merchant_id, o.currency, o.created_at, o.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS merchant_payout_accounts_currency_primary_key
+    ON merchant_payout_accounts (merchant_id, currency) WHERE status = 'active' AND is_primary;